@@ -0,0 +1,151 @@
+//go:build darwin
+
+package sunshine
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// aclXattr names the extended attribute in which macOS stores ACLs.
+const aclXattr = "com.apple.system.Security"
+
+// aclInspector names the command for inspecting ACLs.
+const aclInspector = "ls -le"
+
+// xattrNoFollow directs getxattr to inspect symlinks themselves.
+const xattrNoFollow = 0x0001
+
+// Layout of the kauth_filesec structure, stored in network byte order.
+const (
+	filesecMagic      = 0x012cc16d
+	filesecNoACL      = 0xffffffff
+	filesecHeaderSize = 44
+	filesecEntrySize  = 24
+)
+
+// kauth ACE flags and rights.
+const (
+	aceKindMask     = 0xf
+	acePermit       = 1
+	aceOnlyInherit  = 1 << 8
+	aceReadData     = 1 << 1
+	aceWriteData    = 1 << 2
+	aceAppendData   = 1 << 5
+	aceGenericAll   = 1 << 21
+	aceGenericWrite = 1 << 23
+	aceGenericRead  = 1 << 24
+)
+
+// extendedACL renders the ACL entries of the given path
+// allowing read or write access, by user or group GUID.
+func extendedACL(pth string) ([]string, error) {
+	buf, err := getxattr(pth, aclXattr)
+
+	if err == syscall.ENOATTR || err == syscall.ENOTSUP {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pth, err)
+	}
+
+	if len(buf) < filesecHeaderSize || binary.BigEndian.Uint32(buf) != filesecMagic {
+		return nil, fmt.Errorf("%s: malformed %s attribute", pth, aclXattr)
+	}
+
+	count := binary.BigEndian.Uint32(buf[36:])
+
+	if count == filesecNoACL {
+		return nil, nil
+	}
+
+	if uint64(len(buf)) < filesecHeaderSize+uint64(count)*filesecEntrySize {
+		return nil, fmt.Errorf("%s: malformed %s attribute", pth, aclXattr)
+	}
+
+	var grants []string
+
+	for i := 0; i < int(count); i++ {
+		entry := buf[filesecHeaderSize+i*filesecEntrySize:]
+		flags := binary.BigEndian.Uint32(entry[16:])
+		rights := binary.BigEndian.Uint32(entry[20:])
+
+		if flags&aceKindMask != acePermit || flags&aceOnlyInherit != 0 {
+			continue
+		}
+
+		var granted []string
+
+		if rights&(aceReadData|aceGenericRead|aceGenericAll) != 0 {
+			granted = append(granted, "read")
+		}
+
+		if rights&(aceWriteData|aceAppendData|aceGenericWrite|aceGenericAll) != 0 {
+			granted = append(granted, "write")
+		}
+
+		if len(granted) == 0 {
+			continue
+		}
+
+		grants = append(grants, fmt.Sprintf("%X-%X-%X-%X-%X allow %s", entry[0:4], entry[4:6], entry[6:8], entry[8:10], entry[10:16], strings.Join(granted, ",")))
+	}
+
+	return grants, nil
+}
+
+// getxattr reads an extended attribute without following symlinks.
+func getxattr(pth string, name string) ([]byte, error) {
+	pathPtr, err := syscall.BytePtrFromString(pth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	namePtr, err := syscall.BytePtrFromString(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A nil value buffer queries the attribute size alone.
+	size, _, errno := syscall.Syscall6(
+		syscall.SYS_GETXATTR,
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(namePtr)),
+		0,
+		0,
+		0,
+		xattrNoFollow,
+	)
+
+	if errno != 0 {
+		return nil, errno
+	}
+
+	if size == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, size)
+
+	size, _, errno = syscall.Syscall6(
+		syscall.SYS_GETXATTR,
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(namePtr)),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		0,
+		xattrNoFollow,
+	)
+
+	if errno != 0 {
+		return nil, errno
+	}
+
+	return buf[:size], nil
+}
@@ -0,0 +1,74 @@
+package sunshine
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// aclInspector names the command for inspecting ACLs.
+const aclInspector = "getfacl"
+
+// aclTypeAccess selects POSIX.1e access ACLs, per sys/acl.h.
+const aclTypeAccess = 0x00000002
+
+// aclMaxEntries bounds the entries per ACL, per sys/acl.h.
+const aclMaxEntries = 254
+
+// freebsdACLEntry mirrors struct acl_entry.
+type freebsdACLEntry struct {
+	Tag       uint32
+	ID        uint32
+	Perm      uint32
+	EntryType uint16
+	Flags     uint16
+}
+
+// freebsdACL mirrors struct acl.
+type freebsdACL struct {
+	MaxCount uint32
+	Count    uint32
+	Spare    [4]int32
+	Entries  [aclMaxEntries]freebsdACLEntry
+}
+
+// extendedACL renders the ACL entries of the given path
+// granting read or write access to named users or groups.
+//
+// File systems lacking POSIX.1e ACLs, such as ZFS with NFSv4 ACLs, are skipped.
+func extendedACL(pth string) ([]string, error) {
+	pathPtr, err := syscall.BytePtrFromString(pth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	acl := freebsdACL{MaxCount: aclMaxEntries}
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS___ACL_GET_LINK,
+		uintptr(unsafe.Pointer(pathPtr)),
+		aclTypeAccess,
+		uintptr(unsafe.Pointer(&acl)),
+	)
+
+	switch errno {
+	case 0:
+	case syscall.EOPNOTSUPP, syscall.EINVAL:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s: %w", pth, errno)
+	}
+
+	if acl.Count > aclMaxEntries {
+		return nil, fmt.Errorf("%s: malformed access control list", pth)
+	}
+
+	entries := make([]aclEntry, acl.Count)
+
+	for i, entry := range acl.Entries[:acl.Count] {
+		entries[i] = aclEntry{Tag: entry.Tag, ID: entry.ID, Perm: entry.Perm}
+	}
+
+	return aclGrants(entries), nil
+}
@@ -0,0 +1,103 @@
+package sunshine
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// aclXattr names the extended attribute in which Linux stores access ACLs.
+const aclXattr = "system.posix_acl_access"
+
+// aclXattrVersion denotes the supported ACL extended attribute format.
+const aclXattrVersion = 2
+
+// aclInspector names the command for inspecting ACLs.
+const aclInspector = "getfacl"
+
+// extendedACL renders the ACL entries of the given path
+// granting read or write access to named users or groups.
+func extendedACL(pth string) ([]string, error) {
+	buf, err := lgetxattr(pth, aclXattr)
+
+	if err == syscall.ENODATA || err == syscall.ENOTSUP {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pth, err)
+	}
+
+	if len(buf) == 0 {
+		return nil, nil
+	}
+
+	if len(buf) < 4 || binary.LittleEndian.Uint32(buf) != aclXattrVersion || (len(buf)-4)%8 != 0 {
+		return nil, fmt.Errorf("%s: malformed %s attribute", pth, aclXattr)
+	}
+
+	var entries []aclEntry
+
+	for i := 4; i < len(buf); i += 8 {
+		entries = append(entries, aclEntry{
+			Tag:  uint32(binary.LittleEndian.Uint16(buf[i:])),
+			Perm: uint32(binary.LittleEndian.Uint16(buf[i+2:])),
+			ID:   binary.LittleEndian.Uint32(buf[i+4:]),
+		})
+	}
+
+	return aclGrants(entries), nil
+}
+
+// lgetxattr reads an extended attribute without following symlinks.
+func lgetxattr(pth string, name string) ([]byte, error) {
+	pathPtr, err := syscall.BytePtrFromString(pth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	namePtr, err := syscall.BytePtrFromString(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A zero size queries the attribute size alone.
+	size, _, errno := syscall.Syscall6(
+		syscall.SYS_LGETXATTR,
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(namePtr)),
+		0,
+		0,
+		0,
+		0,
+	)
+
+	if errno != 0 {
+		return nil, errno
+	}
+
+	if size == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, size)
+
+	size, _, errno = syscall.Syscall6(
+		syscall.SYS_LGETXATTR,
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(namePtr)),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		0,
+		0,
+	)
+
+	if errno != 0 {
+		return nil, errno
+	}
+
+	return buf[:size], nil
+}
@@ -0,0 +1,14 @@
+//go:build !darwin && !linux && !freebsd
+
+package sunshine
+
+// aclInspector names the command for inspecting ACLs.
+const aclInspector = ""
+
+// extendedACL renders the ACL entries of the given path
+// granting read or write access to other users or groups.
+//
+// ACL inspection is implemented for Linux, macOS, and FreeBSD only.
+func extendedACL(_ string) ([]string, error) {
+	return nil, nil
+}
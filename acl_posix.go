@@ -0,0 +1,89 @@
+//go:build linux || freebsd
+
+package sunshine
+
+import (
+	"fmt"
+)
+
+// POSIX.1e ACL entry tags, shared by Linux and FreeBSD.
+const (
+	aclUser  = 0x02
+	aclGroup = 0x08
+	aclMask  = 0x10
+)
+
+// POSIX.1e ACL permission bits.
+const (
+	aclRead    = 0x04
+	aclWrite   = 0x02
+	aclExecute = 0x01
+)
+
+// aclEntry models a POSIX.1e ACL entry.
+type aclEntry struct {
+	// Tag classifies the entry.
+	Tag uint32
+
+	// ID denotes the uid or gid of named user and group entries.
+	ID uint32
+
+	// Perm denotes the permission bits.
+	Perm uint32
+}
+
+// aclGrants renders the named user and group entries granting read or write access,
+// as limited by any mask entry, in the notation of getfacl.
+func aclGrants(entries []aclEntry) []string {
+	mask := uint32(aclRead | aclWrite | aclExecute)
+
+	for _, entry := range entries {
+		if entry.Tag == aclMask {
+			mask = entry.Perm
+		}
+	}
+
+	var grants []string
+
+	for _, entry := range entries {
+		var prefix string
+
+		switch entry.Tag {
+		case aclUser:
+			prefix = "user"
+		case aclGroup:
+			prefix = "group"
+		default:
+			continue
+		}
+
+		perm := entry.Perm & mask
+
+		if perm&(aclRead|aclWrite) == 0 {
+			continue
+		}
+
+		grants = append(grants, fmt.Sprintf("%s:%d:%s", prefix, entry.ID, aclPermString(perm)))
+	}
+
+	return grants
+}
+
+// aclPermString renders permission bits as rwx triplets.
+func aclPermString(perm uint32) string {
+	bits := []byte("---")
+
+	if perm&aclRead != 0 {
+		bits[0] = 'r'
+	}
+
+	if perm&aclWrite != 0 {
+		bits[1] = 'w'
+	}
+
+	if perm&aclExecute != 0 {
+		bits[2] = 'x'
+	}
+
+	return string(bits)
+}
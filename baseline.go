@@ -0,0 +1,127 @@
+package sunshine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// LoadBaseline reads the warnings accepted by a baseline file,
+// as written by ReportBaseline, for Scanner.Baseline.
+func LoadBaseline(pth string) (map[Warning]bool, error) {
+	f, err := os.Open(pth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var results []jsonWarning
+
+	if err = json.NewDecoder(f).Decode(&results); err != nil {
+		return nil, fmt.Errorf("%s: %w", pth, err)
+	}
+
+	baseline := make(map[Warning]bool)
+
+	for _, result := range results {
+		severity, err2 := ParseSeverity(result.Severity)
+
+		if err2 != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err2)
+		}
+
+		warning := Warning{
+			Kind:     Kind(result.Rule),
+			Severity: severity,
+			Path:     result.Path,
+			Message:  result.Message,
+			Observed: result.Actual != "",
+		}
+
+		if warning.Actual, err2 = parseOctal(result.Actual); err2 != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err2)
+		}
+
+		warning.Expected = warning.Actual
+
+		if result.Expected != "" {
+			if warning.Expected, err2 = parseOctal(result.Expected); err2 != nil {
+				return nil, fmt.Errorf("%s: %w", pth, err2)
+			}
+		}
+
+		baseline[warning] = true
+	}
+
+	return baseline, nil
+}
+
+// parseOctal reverses octal, treating the empty string as zero.
+func parseOctal(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	bits, err := strconv.ParseUint(s, 8, 32)
+
+	if err != nil || bits > 07777 {
+		return 0, fmt.Errorf("invalid mode %q, expected octal chmod bits such as 0600", s)
+	}
+
+	mode := os.FileMode(bits & 0777)
+
+	if bits&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+
+	if bits&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+
+	if bits&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+
+	return mode, nil
+}
+
+// ReportBaseline renders every warning to the given writer,
+// as a JSON array in the format of ReportJSON, at the end of the scan, in stable order,
+// for LoadBaseline to accept in subsequent scans.
+//
+// Dedupe does not apply, so that every accepted warning is recorded.
+// Errors are rendered to the standard logger as they occur. Debug events and passes are discarded.
+//
+// Returns a nonzero exit code only when errors occurred, regardless of warnings.
+func (o *Scanner) ReportBaseline(w io.Writer) int {
+	status := 0
+	var warnings []Warning
+
+	for {
+		select {
+		case <-o.DebugCh:
+		case <-o.PassCh:
+		case warning := <-o.WarnCh:
+			warnings = append(warnings, warning)
+		case err := <-o.ErrCh:
+			status = 1
+			log.Printf("error: %s\n", err)
+		case <-o.DoneCh:
+			SortWarnings(warnings)
+
+			if err := writeJSON(w, warnings); err != nil {
+				log.Println(err)
+				return 1
+			}
+
+			return status
+		}
+	}
+}
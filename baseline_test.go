@@ -0,0 +1,79 @@
+package sunshine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	home := t.TempDir()
+	mkdirFixture(t, filepath.Join(home, ".ssh"), 0700)
+	credentials := filepath.Join(home, ".aws", "credentials")
+	rsa := filepath.Join(home, ".ssh", "id_rsa")
+	ecdsa := filepath.Join(home, ".ssh", "id_ecdsa")
+	writeFixture(t, credentials, 0644, "")
+	writeFixture(t, rsa, 0644, "")
+	writeFixture(t, rsa+".pub", 0644, "")
+
+	pth := filepath.Join(t.TempDir(), "baseline.json")
+	f, err := os.Create(pth)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := NewScannerWithHome(false, home)
+	scanner.Scan([]string{home})
+
+	if status := scanner.ReportBaseline(f); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := LoadBaseline(pth)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baselined := false
+
+	for warning := range baseline {
+		baselined = baselined || warning.Path == credentials
+	}
+
+	if !baselined {
+		t.Fatalf("%s: expected baselined warnings, got %v", credentials, baseline)
+	}
+
+	if err = os.Chmod(rsa, 0664); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFixture(t, ecdsa, 0644, "")
+	writeFixture(t, ecdsa+".pub", 0644, "")
+
+	scanner = NewScannerWithHome(false, home)
+	scanner.Baseline = baseline
+	warnings := scanWarnings(t, scanner, home)
+
+	if got := warningsFor(warnings, credentials); len(got) != 0 {
+		t.Errorf("%s: expected baselined warnings suppressed, got %v", credentials, got)
+	}
+
+	for pth, actual := range map[string]os.FileMode{rsa: 0664, ecdsa: 0644} {
+		found := false
+
+		for _, warning := range warningsFor(warnings, pth) {
+			found = found || (warning.Kind == KindSSHKey && warning.Actual == actual)
+		}
+
+		if !found {
+			t.Errorf("%s: expected ssh-key warning at %04o, got %v", pth, actual, warnings)
+		}
+	}
+}
@@ -4,68 +4,364 @@ package main
 import (
 	"github.com/mcandre/sunshine"
 
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 )
 
+// stringSlice collects repeated string flags.
+type stringSlice []string
+
+// String renders the collected values.
+func (o *stringSlice) String() string {
+	return strings.Join(*o, ",")
+}
+
+// Set appends a value.
+func (o *stringSlice) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
+
+var flagExclude stringSlice
+var flagKeyPatterns stringSlice
+var flagConfig = flag.String("config", "", "Load settings from the given JSON file (default: .sunshine.json in the working directory, first root, or home directory, when owned by the current user and not writable by group or other)")
 var flagDebug = flag.Bool("debug", false, "Enable additional logging")
+var flagHome = flag.String("home", "", "Analyze against the given home directory (default: current user's home directory)")
+var flagAllUsers = flag.Bool("all-users", false, "Apply home directory policies to the home directory of every user, scanning each by default (requires root on UNIX)")
+var flagKubeConfig = flag.String("kubeconfig", "", "Check the given kubeconfig files, separated as in KUBECONFIG, such as \"$KUBECONFIG\"")
+var flagFiles = flag.Bool("files", false, "Analyze only the given paths, without descending into directories")
+var flagConcurrency = flag.Int("concurrency", 0, "Limit the number of paths inspected at once (0 for the number of CPUs)")
+var flagTimeout = flag.Duration("timeout", 0, "Abort the scan after the given duration, such as 5m, reporting partial results (0 for unlimited)")
+var flagMaxDepth = flag.Int("max-depth", 0, "Limit traversal to the given number of levels below each root (0 for unlimited)")
+var flagSummary = flag.Bool("summary", false, "Conclude with warning counts by file and kind")
+var flagQuiet = flag.Bool("quiet", false, "Suppress individual warnings, implying -summary")
+var flagDedupe = flag.Bool("dedupe", false, "Report only the most severe warning per path")
+var flagVerbose = flag.Bool("verbose", false, "Report sensitive files satisfying permission policies")
+var flagBaseline = flag.String("baseline", "", "Report only warnings absent from the given JSON baseline file, first writing the file when missing")
+var flagFix = flag.Bool("fix", false, "Repair warnings remediable by chmod, summarizing changes and failures")
+var flagDryRun = flag.Bool("dry-run", false, "Render the chmod operations -fix would apply, without modifying anything")
+var flagFormat = flag.String("format", "text", "Render warnings as text, json, or sarif (json and sarif to stdout)")
+var flagSARIF = flag.Bool("sarif", false, "Render a SARIF document to stdout, as -format sarif")
+var flagMinSeverity = flag.String("min-severity", "", "Report only warnings of at least the given severity: info, warning, or error (default info)")
+var flagFailSeverity = flag.String("fail-severity", "", "Exit nonzero only for warnings of at least the given severity: info, warning, or error (default info)")
+var flagBasePath = flag.String("base-path", "", "Render paths relative to the given directory")
+var flagColor = flag.String("color", "auto", "Color warnings and passes: auto, always, or never")
+var flagFollowSymlinks = flag.Bool("follow-symlinks", false, "Analyze symlinks according to their targets")
+var flagWarnMissing = flag.Bool("warn-missing", false, "Warn on .ssh directories lacking config or known_hosts")
+var flagPassphrase = flag.Bool("passphrase", false, "Warn on SSH private keys lacking passphrases")
+var flagWorldWritable = flag.Bool("world-writable", false, "Warn on group-writable and world-writable paths")
 var flagVersion = flag.Bool("version", false, "Show version information")
 var flagHelp = flag.Bool("help", false, "Show usage information")
 
-func main() {
+// run executes the command line, returning an exit code,
+// such that deferred calls complete before main exits.
+func run() int {
+	flag.Var(&flagExclude, "exclude", "Skip paths matching a glob pattern (repeatable)")
+	flag.Var(&flagKeyPatterns, "key-pattern", "Match SSH private key filenames by regular expression (repeatable) (default \"^id_.+$\")")
 	flag.Parse()
 
 	switch {
 	case *flagVersion:
 		fmt.Println(sunshine.Version)
-		os.Exit(0)
+		return 0
 	case *flagHelp:
 		flag.PrintDefaults()
-		os.Exit(0)
+		return 0
 	}
 
 	debug := *flagDebug
 	roots := flag.Args()
 
+	var userHomes []string
+
+	if *flagAllUsers {
+		// Other users' home directories are generally unreadable to non-root users.
+		// Geteuid reports -1 on platforms lacking user IDs, such as Windows.
+		if uid := os.Geteuid(); uid != -1 && uid != 0 {
+			log.Println("-all-users requires root")
+			return 1
+		}
+
+		var err error
+		userHomes, err = sunshine.UserHomes()
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		if len(roots) == 0 {
+			roots = userHomes
+		}
+	}
+
 	if len(roots) == 0 {
 		cwd, err := os.Getwd()
 
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			log.Println(err)
+			return 1
 		}
 
 		roots = []string{cwd}
 	}
 
-	scanner, err := sunshine.Illuminate(roots, debug)
+	kubeConfigs := filepath.SplitList(*flagKubeConfig)
 
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-
-	var msg string
-	clean := true
-
-	for {
-		select {
-		case msg = <-scanner.DebugCh:
-			log.Println(msg)
-		case msg = <-scanner.WarnCh:
-			clean = false
-			log.Printf("warning: %s", msg)
-		case err = <-scanner.ErrCh:
-			clean = false
+	// A lone "-" root reads newline-separated paths from stdin.
+	stdin := len(roots) == 1 && roots[0] == "-"
+	base := roots[0]
+
+	if stdin {
+		base = "."
+	}
+
+	home := *flagHome
+
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+
+		if err != nil {
 			log.Println(err)
-		case <-scanner.DoneCh:
-			if !clean {
-				os.Exit(1)
+			return 1
+		}
+
+		home = userHome
+	}
+
+	configPath := *flagConfig
+
+	if configPath != "" {
+		if _, err := os.Stat(configPath); err != nil {
+			log.Println(err)
+			return 1
+		}
+	} else {
+		// Configuration files in the working directory, first root, or home directory may belong to other users,
+		// as when root scans a user's home directory from within it.
+		var dirs []string
+		seen := make(map[string]bool)
+
+		for _, dir := range []string{".", base, home} {
+			absDir, err := filepath.Abs(dir)
+
+			if err != nil {
+				log.Println(err)
+				return 1
+			}
+
+			if !seen[absDir] {
+				seen[absDir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+
+		for _, dir := range dirs {
+			candidate := sunshine.FindConfig(dir)
+
+			if candidate == "" {
+				continue
+			}
+
+			if !sunshine.TrustedConfig(candidate) {
+				log.Printf("skipping %s, not owned by the current user, or writable by group or other; pass -config to load it\n", candidate)
+				continue
 			}
 
-			os.Exit(0)
+			configPath = candidate
+			break
 		}
 	}
+
+	var scanner *sunshine.Scanner
+
+	if configPath != "" {
+		var err error
+		scanner, err = sunshine.LoadConfig(configPath)
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+	} else {
+		scanner = sunshine.NewScannerWithHome(debug, home)
+	}
+
+	if *flagHome != "" {
+		absHome, err := filepath.Abs(*flagHome)
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		scanner.Home = absHome
+	}
+
+	scanner.Homes = append(scanner.Homes, userHomes...)
+	scanner.KubeConfigs = append(scanner.KubeConfigs, kubeConfigs...)
+
+	if *flagBasePath != "" {
+		scanner.BasePath = *flagBasePath
+	}
+
+	scanner.Debug = scanner.Debug || debug
+	scanner.Ignore = append(scanner.Ignore, flagExclude...)
+
+	for _, keyPattern := range flagKeyPatterns {
+		pattern, err := regexp.Compile(keyPattern)
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		scanner.KeyPatterns = append(scanner.KeyPatterns, pattern)
+	}
+
+	if *flagConcurrency != 0 {
+		scanner.Concurrency = *flagConcurrency
+	}
+
+	if *flagMaxDepth != 0 {
+		scanner.MaxDepth = *flagMaxDepth
+	}
+
+	scanner.Summary = scanner.Summary || *flagSummary
+	scanner.Quiet = scanner.Quiet || *flagQuiet
+	scanner.Dedupe = scanner.Dedupe || *flagDedupe
+	scanner.Verbose = scanner.Verbose || *flagVerbose
+	scanner.FollowSymlinks = scanner.FollowSymlinks || *flagFollowSymlinks
+	scanner.WarnMissing = scanner.WarnMissing || *flagWarnMissing
+	scanner.CheckPassphrase = scanner.CheckPassphrase || *flagPassphrase
+	scanner.CheckWorldWritable = scanner.CheckWorldWritable || *flagWorldWritable
+
+	if *flagMinSeverity != "" {
+		minSeverity, err := sunshine.ParseSeverity(*flagMinSeverity)
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		scanner.MinSeverity = minSeverity
+	}
+
+	if *flagFailSeverity != "" {
+		failSeverity, err := sunshine.ParseSeverity(*flagFailSeverity)
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		scanner.FailSeverity = failSeverity
+	}
+
+	writeBaseline := false
+
+	if *flagBaseline != "" {
+		baseline, err := sunshine.LoadBaseline(*flagBaseline)
+
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			writeBaseline = true
+		case err != nil:
+			log.Println(err)
+			return 1
+		default:
+			scanner.Baseline = baseline
+		}
+	}
+
+	color, err := sunshine.ParseColorMode(*flagColor)
+
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	scanner.Color = color
+	format := *flagFormat
+
+	if *flagSARIF {
+		format = "sarif"
+	}
+
+	if format != "text" && format != "json" && format != "sarif" {
+		log.Printf("unknown format %q, expected text, json, or sarif\n", format)
+		return 1
+	}
+
+	ctx := context.Background()
+
+	if *flagTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *flagTimeout)
+		defer cancel()
+	}
+
+	// Missing kubeconfig files are skipped, as by kubectl.
+	if !stdin {
+		for _, kubeConfig := range kubeConfigs {
+			if _, err := os.Stat(kubeConfig); err == nil {
+				roots = append(roots, kubeConfig)
+			}
+		}
+	}
+
+	switch {
+	case stdin:
+		scanner.ScanReaderContext(ctx, os.Stdin)
+	case *flagFiles:
+		scanner.ScanFilesContext(ctx, roots)
+	default:
+		scanner.ScanContext(ctx, roots)
+	}
+
+	if writeBaseline {
+		f, err := os.Create(*flagBaseline)
+
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		status := scanner.ReportBaseline(f)
+
+		if err = f.Close(); err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		return status
+	}
+
+	if *flagDryRun {
+		return scanner.ReportDryRun(os.Stdout)
+	}
+
+	if *flagFix {
+		return scanner.ReportFix(os.Stderr)
+	}
+
+	switch format {
+	case "json":
+		return scanner.ReportJSON(os.Stdout)
+	case "sarif":
+		return scanner.ReportSARIF(base, os.Stdout)
+	default:
+		return scanner.Report()
+	}
+}
+
+func main() {
+	os.Exit(run())
 }
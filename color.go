@@ -0,0 +1,76 @@
+package sunshine
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ColorMode controls ANSI coloring of reports.
+type ColorMode string
+
+const (
+	// ColorAuto colors reports written to terminals.
+	//
+	// The empty ColorMode behaves as ColorAuto.
+	ColorAuto ColorMode = "auto"
+
+	// ColorAlways colors reports unconditionally.
+	ColorAlways ColorMode = "always"
+
+	// ColorNever disables coloring.
+	ColorNever ColorMode = "never"
+)
+
+// ParseColorMode validates a color mode name.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(s); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown color mode %q, expected auto, always, or never", s)
+	}
+}
+
+// ansiRed begins red text.
+const ansiRed = "\x1b[31m"
+
+// ansiGreen begins green text.
+const ansiGreen = "\x1b[32m"
+
+// ansiReset ends colored text.
+const ansiReset = "\x1b[0m"
+
+// colorize reports whether to color output to the given writer.
+//
+// A nonempty NO_COLOR environment variable disables coloring in any mode.
+func (o ColorMode) colorize(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	switch o {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	f, ok := w.(*os.File)
+
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps text in the given ANSI color, when enabled.
+func paint(enabled bool, color string, s string) string {
+	if !enabled {
+		return s
+	}
+
+	return color + s + ansiReset
+}
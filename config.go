@@ -0,0 +1,267 @@
+package sunshine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigFilename denotes the conventional configuration file basename.
+const ConfigFilename = ".sunshine.json"
+
+// Config models scanner settings persisted as JSON.
+//
+// Relative paths and ignore patterns are resolved
+// against the directory containing the configuration file.
+type Config struct {
+	// Home corresponds to Scanner.Home.
+	Home string `json:"home"`
+
+	// BasePath corresponds to Scanner.BasePath.
+	BasePath string `json:"base-path"`
+
+	// Ignore corresponds to Scanner.Ignore.
+	Ignore []string `json:"ignore"`
+
+	// KeyPatterns corresponds to Scanner.KeyPatterns.
+	KeyPatterns []string `json:"key-patterns"`
+
+	// Rules maps glob patterns to octal modes such as "0600", per Scanner.AddRule.
+	Rules map[string]string `json:"rules"`
+
+	// Allow corresponds to Scanner.Allow, mapping built-in kinds to octal modes such as "0750".
+	// Unknown kinds are rejected.
+	Allow map[string][]string `json:"allow"`
+
+	// Concurrency corresponds to Scanner.Concurrency.
+	Concurrency int `json:"concurrency"`
+
+	// MaxDepth corresponds to Scanner.MaxDepth.
+	MaxDepth int `json:"max-depth"`
+
+	// MinSeverity corresponds to Scanner.MinSeverity, by name.
+	MinSeverity string `json:"min-severity"`
+
+	// FailSeverity corresponds to Scanner.FailSeverity, by name.
+	FailSeverity string `json:"fail-severity"`
+
+	// Summary corresponds to Scanner.Summary.
+	Summary bool `json:"summary"`
+
+	// Quiet corresponds to Scanner.Quiet.
+	Quiet bool `json:"quiet"`
+
+	// Dedupe corresponds to Scanner.Dedupe.
+	Dedupe bool `json:"dedupe"`
+
+	// Verbose corresponds to Scanner.Verbose.
+	Verbose bool `json:"verbose"`
+
+	// FollowSymlinks corresponds to Scanner.FollowSymlinks.
+	FollowSymlinks bool `json:"follow-symlinks"`
+
+	// WarnMissing corresponds to Scanner.WarnMissing.
+	WarnMissing bool `json:"warn-missing"`
+
+	// Passphrase corresponds to Scanner.CheckPassphrase.
+	Passphrase bool `json:"passphrase"`
+
+	// WorldWritable corresponds to Scanner.CheckWorldWritable.
+	WorldWritable bool `json:"world-writable"`
+}
+
+// FindConfig locates the first configuration file among the given directories.
+//
+// Returns the empty string when none exists.
+func FindConfig(dirs ...string) string {
+	for _, dir := range dirs {
+		pth := filepath.Join(dir, ConfigFilename)
+
+		if info, err := os.Stat(pth); err == nil && !info.IsDir() {
+			return pth
+		}
+	}
+
+	return ""
+}
+
+// TrustedConfig reports whether the given configuration file is owned by the current user,
+// and writable by neither group nor other.
+//
+// Configuration files found within scanned directories are loaded only when trusted,
+// lest the owners of those directories hide their own findings, as by ignore patterns,
+// from scans run by others, such as root.
+// Where file ownership is unavailable, as on Windows, no configuration file is trusted.
+func TrustedConfig(pth string) bool {
+	info, err := os.Stat(pth)
+
+	if err != nil {
+		return false
+	}
+
+	uid, ok := owner(info)
+	return ok && uid == os.Geteuid() && info.Mode().Perm()&0022 == 0
+}
+
+// LoadConfig constructs a scanner from the given configuration file.
+//
+// Unknown keys are rejected.
+// When the file does not exist, LoadConfig behaves as NewScanner.
+func LoadConfig(pth string) (*Scanner, error) {
+	f, err := os.Open(pth)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return NewScanner(false)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var config Config
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", pth, err)
+	}
+
+	dir := filepath.Dir(pth)
+	var scanner *Scanner
+
+	if config.Home != "" {
+		scanner = NewScannerWithHome(false, resolve(dir, config.Home))
+	} else if scanner, err = NewScanner(false); err != nil {
+		return nil, err
+	}
+
+	if config.BasePath != "" {
+		scanner.BasePath = resolve(dir, config.BasePath)
+	}
+
+	for _, pattern := range config.Ignore {
+		scanner.Ignore = append(scanner.Ignore, resolve(dir, pattern))
+	}
+
+	for _, keyPattern := range config.KeyPatterns {
+		pattern, err2 := regexp.Compile(keyPattern)
+
+		if err2 != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err2)
+		}
+
+		scanner.KeyPatterns = append(scanner.KeyPatterns, pattern)
+	}
+
+	if config.MinSeverity != "" {
+		if scanner.MinSeverity, err = ParseSeverity(config.MinSeverity); err != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err)
+		}
+	}
+
+	if config.FailSeverity != "" {
+		if scanner.FailSeverity, err = ParseSeverity(config.FailSeverity); err != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err)
+		}
+	}
+
+	kinds := make([]string, 0, len(config.Allow))
+
+	for kind := range config.Allow {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		if Kind(kind).Description() == "" {
+			return nil, fmt.Errorf("%s: allow: unknown kind %q", pth, kind)
+		}
+
+		for _, mode := range config.Allow[kind] {
+			allowedMode, err2 := ParseMode(mode)
+
+			if err2 != nil {
+				return nil, fmt.Errorf("%s: %w", pth, err2)
+			}
+
+			if scanner.Allow == nil {
+				scanner.Allow = make(map[Kind][]os.FileMode)
+			}
+
+			scanner.Allow[Kind(kind)] = append(scanner.Allow[Kind(kind)], allowedMode)
+		}
+	}
+
+	patterns := make([]string, 0, len(config.Rules))
+
+	for pattern := range config.Rules {
+		patterns = append(patterns, pattern)
+	}
+
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		mode, err2 := ParseMode(config.Rules[pattern])
+
+		if err2 != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err2)
+		}
+
+		resolved := pattern
+
+		if pattern != "~" && !strings.HasPrefix(pattern, "~/") {
+			resolved = resolve(dir, strings.TrimSuffix(pattern, "/"))
+
+			if strings.HasSuffix(pattern, "/") {
+				resolved += "/"
+			}
+		}
+
+		if err2 = scanner.AddRule(resolved, mode); err2 != nil {
+			return nil, fmt.Errorf("%s: %w", pth, err2)
+		}
+	}
+
+	scanner.Concurrency = config.Concurrency
+	scanner.MaxDepth = config.MaxDepth
+	scanner.Summary = config.Summary
+	scanner.Quiet = config.Quiet
+	scanner.Dedupe = config.Dedupe
+	scanner.Verbose = config.Verbose
+	scanner.FollowSymlinks = config.FollowSymlinks
+	scanner.WarnMissing = config.WarnMissing
+	scanner.CheckPassphrase = config.Passphrase
+	scanner.CheckWorldWritable = config.WorldWritable
+	return scanner, nil
+}
+
+// ParseMode validates octal permission bits, such as "0750".
+func ParseMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid mode %q, expected octal permission bits such as 0750", s)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// resolve anchors relative paths to the given directory.
+func resolve(dir string, pth string) string {
+	if filepath.IsAbs(pth) {
+		return pth
+	}
+
+	return filepath.Join(dir, pth)
+}
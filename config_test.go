@@ -0,0 +1,86 @@
+package sunshine
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestTrustedConfig(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("file ownership unavailable")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		mode    os.FileMode
+		uid     int
+		trusted bool
+	}{
+		{"owned", 0644, -1, true},
+		{"group-writable", 0664, -1, false},
+		{"world-writable", 0646, -1, false},
+		{"foreign", 0644, 12345, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), ConfigFilename)
+			writeFixture(t, pth, tc.mode, "{}")
+
+			if tc.uid != -1 {
+				if err := os.Chown(pth, tc.uid, -1); err != nil {
+					t.Skip(err)
+				}
+			}
+
+			if trusted := TrustedConfig(pth); trusted != tc.trusted {
+				t.Errorf("expected trusted %v, got %v", tc.trusted, trusted)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAllow(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config string
+		warn   bool
+		err    bool
+	}{
+		{"default", `{}`, true, false},
+		{"override", `{"allow": {"ssh-dir": ["0750"]}}`, false, false},
+		{"other mode", `{"allow": {"ssh-dir": ["0710"]}}`, true, false},
+		{"unknown kind", `{"allow": {"ssh-kye": ["0750"]}}`, false, true},
+		{"invalid mode", `{"allow": {"ssh-dir": ["0999"]}}`, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			home := t.TempDir()
+			sshDir := filepath.Join(home, ".ssh")
+			mkdirFixture(t, sshDir, 0750)
+			configPath := filepath.Join(t.TempDir(), ConfigFilename)
+			writeFixture(t, configPath, 0600, tc.config)
+			scanner, err := LoadConfig(configPath)
+
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			scanner.Home = home
+			var warnings []Warning
+
+			for _, warning := range warningsFor(scanWarnings(t, scanner, home), sshDir) {
+				if warning.Kind == KindSSHDir {
+					warnings = append(warnings, warning)
+				}
+			}
+
+			if warn := len(warnings) != 0; warn != tc.warn {
+				t.Errorf("expected warnings %v, got %v", tc.warn, warnings)
+			}
+		})
+	}
+}
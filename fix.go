@@ -0,0 +1,363 @@
+package sunshine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// chmodMask selects the mode bits governed by chmod.
+const chmodMask = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+
+// chmodBits extracts the chmod governed bits of a path.
+func chmodBits(info os.FileInfo) os.FileMode {
+	return info.Mode() & chmodMask
+}
+
+// octal renders chmod governed bits in the numeric notation of chmod(1),
+// such that setuid, setgid, and sticky bits occupy the leading digit.
+func octal(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+
+	if mode&os.ModeSetuid != 0 {
+		bits |= 04000
+	}
+
+	if mode&os.ModeSetgid != 0 {
+		bits |= 02000
+	}
+
+	if mode&os.ModeSticky != 0 {
+		bits |= 01000
+	}
+
+	return bits
+}
+
+// Chmod describes a planned permission change.
+type Chmod struct {
+	// Path denotes the file path concerned, as rendered in warnings.
+	Path string
+
+	// From denotes the observed chmod bits.
+	From os.FileMode
+
+	// To denotes the remediating chmod bits.
+	To os.FileMode
+}
+
+// String renders a chmod.
+func (o Chmod) String() string {
+	return fmt.Sprintf("chmod %04o %s", octal(o.To), o.Path)
+}
+
+// invisibleMask bounds the bits that Plan may set,
+// being the owner read and traversal bits required by KindInvisible.
+const invisibleMask os.FileMode = 0500
+
+// Plan derives chmod operations remediating the given warnings,
+// one per path, in order of first appearance.
+//
+// Chmod operations only clear bits, never granting further access,
+// save for the owner read and traversal bits required by KindInvisible warnings.
+// Warnings remediable only by granting further access, such as a 0400 file expected at 0600,
+// are left unremediated.
+//
+// Remediations of multiple warnings concerning the same path are combined,
+// clearing the union of their respective bits.
+// Warnings that chmod alone cannot remediate are skipped.
+func Plan(warnings []Warning) []Chmod {
+	var paths []string
+	froms := make(map[string]os.FileMode)
+	clears := make(map[string]os.FileMode)
+	sets := make(map[string]os.FileMode)
+
+	for _, warning := range warnings {
+		if warning.Expected == warning.Actual {
+			continue
+		}
+
+		if _, ok := froms[warning.Path]; !ok {
+			paths = append(paths, warning.Path)
+			froms[warning.Path] = warning.Actual
+		}
+
+		clears[warning.Path] |= warning.Actual &^ warning.Expected
+
+		if warning.Kind == KindInvisible {
+			sets[warning.Path] |= (warning.Expected &^ warning.Actual) & invisibleMask
+		}
+	}
+
+	var chmods []Chmod
+
+	for _, pth := range paths {
+		chmod := Chmod{Path: pth, From: froms[pth], To: froms[pth]&^clears[pth] | sets[pth]}
+
+		if chmod.To != chmod.From {
+			chmods = append(chmods, chmod)
+		}
+	}
+
+	return chmods
+}
+
+// remediates reports whether the given chmod bits resolve a warning,
+// clearing its excess bits and setting its missing bits.
+func remediates(mode os.FileMode, warning Warning) bool {
+	missing := warning.Expected &^ warning.Actual
+	return mode&(warning.Actual&^warning.Expected) == 0 && mode&missing == missing
+}
+
+// Apply performs a planned chmod.
+//
+// Paths rendered relative to BasePath are resolved against it.
+// Paths within a scanned fs.FS cannot be modified.
+//
+// The path is described afresh, without following symlinks, immediately prior to the chmod,
+// refusing anything other than regular files and directories, such as symlinks,
+// lest the chmod apply to a symlink target.
+// The bits cleared and set by the planned chmod are applied to the current mode,
+// rather than to the mode observed during the scan.
+func (o Scanner) Apply(chmod Chmod) error {
+	_, err := o.apply(chmod)
+	return err
+}
+
+// apply performs a planned chmod, per Apply,
+// returning the chmod applied, from the current mode.
+func (o Scanner) apply(chmod Chmod) (Chmod, error) {
+	if o.fsys != nil {
+		return chmod, fmt.Errorf("%s: chmod unavailable within fs.FS", chmod.Path)
+	}
+
+	pth := o.resolvePath(chmod.Path)
+	info, err := os.Lstat(pth)
+
+	if err != nil {
+		return chmod, err
+	}
+
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		return chmod, fmt.Errorf("%s: refusing to chmod %s, expected regular file or directory", chmod.Path, info.Mode().Type())
+	}
+
+	applied := Chmod{Path: chmod.Path, From: chmodBits(info)}
+	applied.To = applied.From&^(chmod.From&^chmod.To) | chmod.To&^chmod.From
+
+	if applied.To == applied.From {
+		return applied, nil
+	}
+
+	return applied, os.Chmod(pth, applied.To)
+}
+
+// resolvePath reverses Relative, resolving paths rendered relative to BasePath.
+func (o Scanner) resolvePath(pth string) string {
+	if o.BasePath == "" {
+		return pth
+	}
+
+	return resolve(o.BasePath, pth)
+}
+
+// plan derives the chmod operations remediating the given warnings, per Plan,
+// restricted to paths within the scan roots,
+// sparing paths such as the ancestors of the home directory,
+// as well as paths reached through symlinked directories within the scan roots.
+func (o Scanner) plan(warnings []Warning) []Chmod {
+	var chmods []Chmod
+
+	for _, chmod := range Plan(warnings) {
+		pth := o.resolvePath(chmod.Path)
+
+		for _, root := range o.roots {
+			if within(root, pth) && !linkedWithin(root, pth) {
+				chmods = append(chmods, chmod)
+				break
+			}
+		}
+	}
+
+	return chmods
+}
+
+// linkedWithin reports whether any parent directory of a path, below the given root, is a symlink,
+// or cannot be described.
+func linkedWithin(root string, pth string) bool {
+	absRoot, err := filepath.Abs(root)
+
+	if err != nil {
+		return true
+	}
+
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return true
+	}
+
+	for dir := filepath.Dir(absPath); within(absRoot, dir) && dir != absRoot; dir = filepath.Dir(dir) {
+		info, err2 := os.Lstat(dir)
+
+		if err2 != nil || info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fix plans and applies the chmod operations remediating the given warnings,
+// returning those applied, and the errors of those failed.
+func (o Scanner) fix(warnings []Warning) ([]Chmod, []error) {
+	var changed []Chmod
+	var errs []error
+
+	for _, chmod := range o.plan(warnings) {
+		applied, err := o.apply(chmod)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		changed = append(changed, applied)
+	}
+
+	return changed, errs
+}
+
+// Fix pours through the given file path recursively,
+// repairing permission discrepancies that chmod alone can remediate,
+// as planned by Plan.
+//
+// Paths outside of the root, such as the ancestors of the home directory, are left untouched.
+//
+// Returns the chmod operations applied, along with scan errors and failed chmod operations, joined.
+// Failures for want of privileges satisfy errors.Is(err, fs.ErrPermission).
+func (o *Scanner) Fix(root string) ([]Chmod, error) {
+	o.Scan([]string{root})
+	warnings, err := o.collect()
+	changed, errs := o.fix(warnings)
+	return changed, errors.Join(append([]error{err}, errs...)...)
+}
+
+// drain gathers warnings until the end of the scan,
+// rendering debug events and errors as they occur.
+//
+// Returns the warnings in stable order, along with a nonzero exit code when any errors occurred.
+func (o *Scanner) drain(logger *log.Logger, color bool) ([]Warning, int) {
+	status := 0
+	var warnings []Warning
+
+	for {
+		select {
+		case msg := <-o.DebugCh:
+			logger.Println(msg)
+		case warning := <-o.WarnCh:
+			warnings = append(warnings, warning)
+		case <-o.PassCh:
+		case err := <-o.ErrCh:
+			status = 1
+			logger.Println(paint(color, ansiRed, fmt.Sprintf("error: %s", err)))
+		case <-o.DoneCh:
+			SortWarnings(warnings)
+			return warnings, status
+		}
+	}
+}
+
+// ReportDryRun renders the chmod operations that ReportFix would apply
+// to the given writer, at the end of the scan, without modifying any paths.
+//
+// Errors are rendered as they occur.
+// Warnings and passes are otherwise discarded.
+//
+// Returns a nonzero exit code only when errors occurred,
+// regardless of warnings, as nothing is modified.
+func (o *Scanner) ReportDryRun(w io.Writer) int {
+	logger := log.New(w, "", log.LstdFlags)
+	warnings, status := o.drain(logger, o.Color.colorize(w))
+
+	for _, chmod := range o.plan(warnings) {
+		logger.Println(fmt.Sprintf("would %s (currently %04o)", chmod, octal(chmod.From)))
+	}
+
+	return status
+}
+
+// ReportFix repairs permission discrepancies found by the scan,
+// as Fix does, rendering the chmod operations applied to the given writer,
+// followed by failed chmod operations, any remaining warnings,
+// and a closing summary.
+//
+// Errors are rendered as they occur.
+//
+// Returns a nonzero exit code when any errors or failed chmod operations occurred,
+// or any remaining warnings of at least FailSeverity.
+func (o *Scanner) ReportFix(w io.Writer) int {
+	logger := log.New(w, "", log.LstdFlags)
+	color := o.Color.colorize(w)
+	warnings, status := o.drain(logger, color)
+	changed, errs := o.fix(warnings)
+	fixed := make(map[string]os.FileMode)
+
+	for _, chmod := range changed {
+		fixed[chmod.Path] = chmod.To
+		logger.Println(paint(color, ansiGreen, fmt.Sprintf("fixed: %s (was %04o)", chmod, octal(chmod.From))))
+	}
+
+	denied := 0
+
+	for _, err := range errs {
+		status = 1
+
+		if errors.Is(err, fs.ErrPermission) {
+			denied++
+		}
+
+		logger.Println(paint(color, ansiRed, fmt.Sprintf("error: %s", err)))
+	}
+
+	var remaining []Warning
+
+	for _, warning := range warnings {
+		if mode, ok := fixed[warning.Path]; ok && warning.Expected != warning.Actual && remediates(mode, warning) {
+			continue
+		}
+
+		if warning.Severity >= o.FailSeverity {
+			status = 1
+		}
+
+		remaining = append(remaining, warning)
+	}
+
+	if o.Dedupe {
+		remaining = DedupeWarnings(remaining)
+	}
+
+	if !o.Quiet {
+		for _, warning := range remaining {
+			logger.Println(paint(color, ansiRed, fmt.Sprintf("warning: %s", warning)))
+		}
+	}
+
+	summary := fmt.Sprintf("fixed %d of %d paths", len(changed), len(changed)+len(errs))
+
+	if denied != 0 {
+		summary += fmt.Sprintf(", %d permission denied", denied)
+	}
+
+	if other := len(errs) - denied; other != 0 {
+		summary += fmt.Sprintf(", %d failed otherwise", other)
+	}
+
+	logger.Println(summary)
+	return status
+}
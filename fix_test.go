@@ -0,0 +1,189 @@
+package sunshine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixSymlinks(t *testing.T) {
+	for _, followSymlinks := range []bool{false, true} {
+		t.Run(fmt.Sprintf("follow %v", followSymlinks), func(t *testing.T) {
+			root := t.TempDir()
+			secret := filepath.Join(root, "etc", "secret")
+			writeFixture(t, secret, 0600, "")
+
+			if err := os.Symlink(secret, filepath.Join(root, "known_hosts")); err != nil {
+				t.Skip(err)
+			}
+
+			scanner := NewScannerWithHome(false, root)
+			scanner.FollowSymlinks = followSymlinks
+
+			if _, err := scanner.Fix(root); err != nil {
+				t.Error(err)
+			}
+
+			info, err := os.Stat(secret)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if mode := info.Mode().Perm(); mode != 0600 {
+				t.Errorf("expected symlink target to remain chmod 0600, got %04o", mode)
+			}
+		})
+	}
+}
+
+func TestApplyRefusesSymlinks(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target")
+	link := filepath.Join(root, "link")
+	writeFixture(t, target, 0644, "")
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip(err)
+	}
+
+	scanner := NewScannerWithHome(false, root)
+
+	if err := scanner.Apply(Chmod{Path: link, From: 0777, To: 0700}); err == nil {
+		t.Error("expected refusal to chmod symlink")
+	}
+
+	info, err := os.Stat(target)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode != 0644 {
+		t.Errorf("expected symlink target to remain chmod 0644, got %04o", mode)
+	}
+}
+
+func TestFixSymlinkedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	key := filepath.Join(dir, "dotfiles", "ssh", "id_rsa")
+	writeFixture(t, key, 0644, "")
+	mkdirFixture(t, home, 0700)
+
+	if err := os.Symlink(filepath.Dir(key), filepath.Join(home, ".ssh")); err != nil {
+		t.Skip(err)
+	}
+
+	scanner := NewScannerWithHome(false, home)
+	scanner.FollowSymlinks = true
+	changed, _ := scanner.Fix(home)
+
+	if len(changed) != 0 {
+		t.Errorf("expected no chmods through symlinked directory, got %v", changed)
+	}
+
+	info, err := os.Stat(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode != 0644 {
+		t.Errorf("expected key beneath symlinked directory to remain chmod 0644, got %04o", mode)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		warnings []Warning
+		chmods   []Chmod
+	}{
+		{
+			name:     "clear excess bits",
+			warnings: []Warning{{Kind: KindSSHKey, Path: "id_rsa", Actual: 0644, Expected: 0600}},
+			chmods:   []Chmod{{Path: "id_rsa", From: 0644, To: 0600}},
+		},
+		{
+			name:     "never grant further access",
+			warnings: []Warning{{Kind: KindSSHAuthorizedKeys, Path: "authorized_keys", Actual: 0400, Expected: 0600}},
+		},
+		{
+			name:     "clear without granting",
+			warnings: []Warning{{Kind: KindSSHKnownHosts, Path: "known_hosts", Actual: 0606, Expected: 0644}},
+			chmods:   []Chmod{{Path: "known_hosts", From: 0606, To: 0604}},
+		},
+		{
+			name:     "owner read for invisible files",
+			warnings: []Warning{{Kind: KindInvisible, Path: "notes", Actual: 0200, Expected: 0600}},
+			chmods:   []Chmod{{Path: "notes", From: 0200, To: 0600}},
+		},
+		{
+			name:     "owner read only for invisible files",
+			warnings: []Warning{{Kind: KindInvisible, Path: "notes", Actual: 0000, Expected: 0644}},
+			chmods:   []Chmod{{Path: "notes", From: 0000, To: 0400}},
+		},
+		{
+			name: "combine warnings",
+			warnings: []Warning{
+				{Kind: KindSSHKey, Path: "id_rsa", Actual: os.ModeSetuid | 0666, Expected: os.ModeSetuid | 0600},
+				{Kind: KindSpecialBits, Path: "id_rsa", Actual: os.ModeSetuid | 0666, Expected: 0666},
+			},
+			chmods: []Chmod{{Path: "id_rsa", From: os.ModeSetuid | 0666, To: 0600}},
+		},
+		{
+			name:     "skip unremediable warnings",
+			warnings: []Warning{{Kind: KindOwnership, Path: "id_rsa", Actual: 0600, Expected: 0600}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chmods := Plan(tc.warnings)
+
+			if len(chmods) != len(tc.chmods) {
+				t.Errorf("expected %v, got %v", tc.chmods, chmods)
+				return
+			}
+
+			for i, chmod := range chmods {
+				if chmod != tc.chmods[i] {
+					t.Errorf("expected %#v, got %#v", tc.chmods[i], chmod)
+				}
+			}
+		})
+	}
+}
+
+func TestFixOnlyClears(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "alice")
+	mkdirFixture(t, home, 0700)
+	modes := map[string]os.FileMode{
+		".ssh/known_hosts":     0600,
+		".ssh/authorized_keys": 0400,
+		".ssh/id_rsa":          0644,
+	}
+
+	for rel, mode := range modes {
+		writeFixture(t, filepath.Join(home, filepath.FromSlash(rel)), mode, "")
+	}
+
+	if _, err := NewScannerWithHome(false, home).Fix(home); err != nil {
+		t.Error(err)
+	}
+
+	modes["."] = 0700
+	modes[".ssh/id_rsa"] = 0600
+
+	for rel, expected := range modes {
+		info, err := os.Stat(filepath.Join(home, filepath.FromSlash(rel)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mode := info.Mode().Perm(); mode != expected {
+			t.Errorf("%s: expected chmod %04o, got %04o", rel, expected, mode)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package sunshine
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fsPath translates an absolute scan path to its name within the scanned fs.FS.
+func fsPath(pth string) string {
+	name := strings.TrimPrefix(path.Clean(filepath.ToSlash(pth)), "/")
+
+	if name == "" {
+		return "."
+	}
+
+	return name
+}
+
+// stat follows symlinks to describe a path,
+// within the scanned fs.FS, if any.
+func (o Scanner) stat(pth string) (os.FileInfo, error) {
+	if o.fsys != nil {
+		return fs.Stat(o.fsys, fsPath(pth))
+	}
+
+	return os.Stat(pth)
+}
+
+// lstat describes a path without following symlinks,
+// within the scanned fs.FS, if any.
+//
+// fs.FS lacks symlink awareness, such that lstat follows symlinks there.
+func (o Scanner) lstat(pth string) (os.FileInfo, error) {
+	if o.fsys != nil {
+		return fs.Stat(o.fsys, fsPath(pth))
+	}
+
+	return os.Lstat(pth)
+}
+
+// open opens a file for reading,
+// within the scanned fs.FS, if any.
+func (o Scanner) open(pth string) (fs.File, error) {
+	if o.fsys != nil {
+		return o.fsys.Open(fsPath(pth))
+	}
+
+	return os.Open(pth)
+}
+
+// glob expands a filepath.Match pattern,
+// within the scanned fs.FS, if any.
+func (o Scanner) glob(pattern string) ([]string, error) {
+	if o.fsys == nil {
+		return filepath.Glob(pattern)
+	}
+
+	matches, err := fs.Glob(o.fsys, fsPath(pattern))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, match := range matches {
+		matches[i] = "/" + match
+	}
+
+	return matches, nil
+}
+
+// ScanFS pours through the given fs.FS recursively
+// for known permission discrepancies, beginning at root,
+// in the background.
+//
+// The file system is treated as mounted at "/",
+// such that the root "home/alice" reports paths like "/home/alice/.ssh",
+// and Home and Ignore are expressed in these terms.
+// Hermetic file systems such as fstest.MapFS enable testing checks against exact modes.
+//
+// Lacking symlink support in fs.FS, FollowSymlinks is ignored,
+// and file system type detection is skipped.
+//
+// Scan the file system once per scanner.
+func (o *Scanner) ScanFS(fsys fs.FS, root string) {
+	o.ScanFSContext(context.Background(), fsys, root)
+}
+
+// ScanFSContext pours through the given fs.FS recursively, as ScanFS does.
+//
+// Cancelling the context stops the walk promptly,
+// signaling an error wrapping the context error.
+func (o *Scanner) ScanFSContext(ctx context.Context, fsys fs.FS, root string) {
+	o.fsys = fsys
+	absRoot := "/" + fsPath(root)
+
+	if absRoot == "/." {
+		absRoot = "/"
+	}
+
+	go func() {
+		walk := o.Walk(absRoot)
+
+		if err := fs.WalkDir(fsys, fsPath(root), func(name string, d fs.DirEntry, walkErr error) error {
+			pth := path.Join("/", name)
+
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("%s: scan aborted: %w", absRoot, err)
+			}
+
+			if d == nil {
+				return walk(pth, nil, walkErr)
+			}
+
+			info, err := d.Info()
+
+			if err != nil {
+				return walk(pth, nil, err)
+			}
+
+			return walk(pth, info, walkErr)
+		}); err != nil {
+			o.ErrCh <- err
+		}
+
+		if ctx.Err() == nil {
+			o.ScanSSHKeyPairs()
+		}
+		o.DoneCh <- struct{}{}
+	}()
+}
@@ -0,0 +1,103 @@
+package sunshine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+// homeFS models a file system holding the home directory /home/alice,
+// with the given files beneath it.
+func homeFS(files map[string]fs.FileMode) fstest.MapFS {
+	fsys := fstest.MapFS{
+		"home/alice":      {Mode: fs.ModeDir | 0755},
+		"home/alice/.ssh": {Mode: fs.ModeDir | 0700},
+	}
+
+	for name, mode := range files {
+		fsys["home/alice/"+name] = &fstest.MapFile{Mode: mode}
+	}
+
+	return fsys
+}
+
+// scanFSWarnings scans an fs.FS to completion,
+// failing the test upon any scan errors.
+func scanFSWarnings(t *testing.T, fsys fs.FS, root string) []Warning {
+	t.Helper()
+	scanner := NewScannerWithHome(false, "/home/alice")
+	scanner.ScanFS(fsys, root)
+	warnings, err := scanner.Collect()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return warnings
+}
+
+func TestScanFS(t *testing.T) {
+	for _, tc := range []struct {
+		mode     fs.FileMode
+		expected bool
+	}{
+		{0600, false},
+		{0400, false},
+		{0640, true},
+		{0644, true},
+	} {
+		warnings := scanFSWarnings(t, homeFS(map[string]fs.FileMode{".ssh/id_rsa": tc.mode}), "home/alice")
+		var matched []Warning
+
+		for _, warning := range warningsFor(warnings, "/home/alice/.ssh/id_rsa") {
+			if warning.Kind == KindSSHKey {
+				matched = append(matched, warning)
+			}
+		}
+
+		if !tc.expected {
+			if len(matched) != 0 {
+				t.Errorf("%04o: expected no warnings, got %v", tc.mode, matched)
+			}
+
+			continue
+		}
+
+		if len(matched) != 1 {
+			t.Errorf("%04o: expected one warning, got %v", tc.mode, matched)
+			continue
+		}
+
+		if matched[0].Actual != tc.mode || matched[0].Expected != 0600 {
+			t.Errorf("%04o: expected actual %04o and expected 0600, got %v", tc.mode, tc.mode, matched[0])
+		}
+	}
+}
+
+func TestScanFSRoot(t *testing.T) {
+	fsys := homeFS(map[string]fs.FileMode{".ssh/id_rsa": 0644})
+
+	for _, root := range []string{".", "/", "home", "/home/alice"} {
+		if len(warningsFor(scanFSWarnings(t, fsys, root), "/home/alice/.ssh/id_rsa")) == 0 {
+			t.Errorf("root %q: expected warnings for /home/alice/.ssh/id_rsa", root)
+		}
+	}
+}
+
+func TestScanFSContextCancelled(t *testing.T) {
+	scanner := NewScannerWithHome(false, "/home/alice")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	scanner.ScanFSContext(ctx, homeFS(map[string]fs.FileMode{".ssh/id_rsa": 0644}), "home/alice")
+	warnings, err := scanner.Collect()
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
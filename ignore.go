@@ -0,0 +1,269 @@
+package sunshine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFilename denotes per-directory ignore files,
+// listing gitignore-style patterns relative to their directory, one per line.
+//
+// Patterns lacking a slash match names at any depth,
+// whereas patterns containing a slash match paths relative to the directory.
+// ** matches any number of directories, and a trailing ** matches everything within a directory, but not the directory itself.
+// A trailing slash matches directories alone,
+// and a leading ! re-includes paths ignored by earlier patterns or by ancestor ignore files,
+// including entries of ignored directories, when the pattern names the directory, as with !fixtures/README.md.
+// Blank lines and lines beginning with # are skipped.
+const IgnoreFilename = ".sunshineignore"
+
+// ignorePattern models a compiled line of an ignore file.
+type ignorePattern struct {
+	// glob denotes the slash separated pattern, relative to the directory of the ignore file.
+	glob string
+
+	// negated re-includes matching paths.
+	negated bool
+
+	// dirOnly restricts matches to directories.
+	dirOnly bool
+}
+
+// compileIgnorePattern interprets a line of an ignore file.
+func compileIgnorePattern(line string) ignorePattern {
+	var pattern ignorePattern
+
+	if strings.HasPrefix(line, "!") {
+		pattern.negated = true
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		pattern.glob = strings.TrimPrefix(line, "/")
+	} else {
+		pattern.glob = "**/" + line
+	}
+
+	return pattern
+}
+
+// ParseIgnoreFile reads the patterns of an ignore file, slash separated.
+func (o Scanner) ParseIgnoreFile(pth string) ([]string, error) {
+	f, err := o.open(pth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var patterns []string
+	lines := bufio.NewScanner(f)
+
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if _, err = path.Match(strings.TrimPrefix(line, "!"), ""); err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", pth, line, err)
+		}
+
+		patterns = append(patterns, filepath.ToSlash(line))
+	}
+
+	return patterns, lines.Err()
+}
+
+// loadIgnoreFile registers the patterns of the ignore file
+// in the given directory, if any, for its descendants.
+//
+// Ignore files in unreadable directories are treated as absent,
+// leaving the walk to report the directory itself.
+func (o *Scanner) loadIgnoreFile(dir string) error {
+	if o.mu == nil {
+		return nil
+	}
+
+	patterns, err := o.ParseIgnoreFile(filepath.Join(dir, IgnoreFilename))
+
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	absDir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.ignoreFiles == nil {
+		o.ignoreFiles = make(map[string][]ignorePattern)
+	}
+
+	compiled := make([]ignorePattern, len(patterns))
+
+	for i, pattern := range patterns {
+		compiled[i] = compileIgnorePattern(pattern)
+	}
+
+	o.ignoreFiles[absDir] = compiled
+	return nil
+}
+
+// ignoredByFiles reports whether the given absolute path
+// matches patterns loaded from the ignore files of its ancestors.
+//
+// As with gitignore, the last matching pattern of the nearest ignore file prevails.
+// Paths matching no pattern inherit the status of their nearest matching ancestor directory,
+// such that the entries of ignored directories remain ignored, save for those re-included by ! patterns.
+func (o Scanner) ignoredByFiles(absPath string) bool {
+	if o.mu == nil {
+		return false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.ignoreFiles) == 0 {
+		return false
+	}
+
+	if ignored, matched := o.matchIgnoreFiles(absPath, func() bool { return o.isDir(absPath) }); matched {
+		return ignored
+	}
+
+	isDir := func() bool { return true }
+
+	for dir := filepath.Dir(absPath); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if ignored, matched := o.matchIgnoreFiles(dir, isDir); matched {
+			return ignored
+		}
+	}
+
+	return false
+}
+
+// matchIgnoreFiles applies the patterns of the ignore files of the ancestors of the given absolute path,
+// reporting whether the path is ignored, and whether any pattern matched.
+//
+// isDir is queried only for patterns restricted to directories.
+//
+// Callers hold mu.
+func (o Scanner) matchIgnoreFiles(absPath string, isDir func() bool) (bool, bool) {
+	for dir := filepath.Dir(absPath); ; dir = filepath.Dir(dir) {
+		if patterns, ok := o.ignoreFiles[dir]; ok {
+			rel, err := filepath.Rel(dir, absPath)
+
+			if err == nil {
+				rel = filepath.ToSlash(rel)
+
+				for i := len(patterns) - 1; i >= 0; i-- {
+					pattern := patterns[i]
+
+					if !matchGlob(pattern.glob, rel) || (pattern.dirOnly && !isDir()) {
+						continue
+					}
+
+					return !pattern.negated, true
+				}
+			}
+		}
+
+		if dir == filepath.Dir(dir) {
+			return false, false
+		}
+	}
+}
+
+// reincludable reports whether an ignored directory may hold paths re-included by ! patterns
+// of the ignore files of its ancestors, such that walks descend into it rather than pruning it.
+//
+// Directories matching Ignore are never reincludable.
+func (o Scanner) reincludable(pth string) (bool, error) {
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return false, err
+	}
+
+	if ignored, err := o.ignoredByOptions(absPath); err != nil || ignored {
+		return false, err
+	}
+
+	if o.mu == nil {
+		return false, nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for dir := filepath.Dir(absPath); ; dir = filepath.Dir(dir) {
+		if patterns, ok := o.ignoreFiles[dir]; ok {
+			rel, err2 := filepath.Rel(dir, absPath)
+
+			if err2 == nil {
+				names := strings.Split(filepath.ToSlash(rel), "/")
+
+				for _, pattern := range patterns {
+					if pattern.negated && matchPrefix(strings.Split(pattern.glob, "/"), names) {
+						return true, nil
+					}
+				}
+			}
+		}
+
+		if dir == filepath.Dir(dir) {
+			return false, nil
+		}
+	}
+}
+
+// matchPrefix reports whether glob pattern segments may match paths strictly beneath the given path segments.
+//
+// ** only matches beneath paths already matched by leading segments of the pattern,
+// such that patterns lacking a slash, as by !name, never hold ignored directories open.
+func matchPrefix(patterns []string, names []string) bool {
+	for i := 0; i < len(names); i++ {
+		if i == len(patterns) {
+			return false
+		}
+
+		if patterns[i] == "**" {
+			return i != 0
+		}
+
+		if match, _ := path.Match(patterns[i], names[i]); !match {
+			return false
+		}
+	}
+
+	return len(patterns) > len(names)
+}
+
+// isDir reports whether the given path denotes a directory, without following symlinks.
+func (o Scanner) isDir(pth string) bool {
+	info, err := o.lstat(pth)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,141 @@
+package sunshine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreFile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ignore  string
+		ignored []string
+		scanned []string
+	}{
+		{
+			name:    "whole directory",
+			ignore:  "fixtures/\n",
+			ignored: []string{"fixtures/.ssh", "fixtures/.ssh/id_rsa", "fixtures/.ssh/id_ecdsa", "fixtures/keys/.ssh/id_ed25519"},
+		},
+		{
+			name:    "re-included file",
+			ignore:  "fixtures/\n!fixtures/.ssh/id_rsa\n",
+			ignored: []string{"fixtures/.ssh", "fixtures/.ssh/id_ecdsa", "fixtures/keys/.ssh/id_ed25519"},
+			scanned: []string{"fixtures/.ssh/id_rsa"},
+		},
+		{
+			name:    "name within an ignored directory",
+			ignore:  "fixtures/\n!id_ecdsa\n",
+			ignored: []string{"fixtures/.ssh", "fixtures/.ssh/id_rsa", "fixtures/.ssh/id_ecdsa", "fixtures/keys/.ssh/id_ed25519"},
+		},
+		{
+			name:    "re-included directory",
+			ignore:  "fixtures/\n!fixtures/keys/\n",
+			ignored: []string{"fixtures/.ssh", "fixtures/.ssh/id_rsa", "fixtures/.ssh/id_ecdsa"},
+			scanned: []string{"fixtures/keys/.ssh/id_ed25519"},
+		},
+		{
+			name:    "trailing double star",
+			ignore:  "fixtures/.ssh/**\n",
+			ignored: []string{"fixtures/.ssh/id_rsa", "fixtures/.ssh/id_ecdsa"},
+			scanned: []string{"fixtures/.ssh", "fixtures/keys/.ssh/id_ed25519"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			home := t.TempDir()
+			mkdirFixture(t, filepath.Join(home, "fixtures", ".ssh"), 0777)
+			writeFixture(t, filepath.Join(home, "fixtures", ".ssh", "id_rsa"), 0644, "")
+			writeFixture(t, filepath.Join(home, "fixtures", ".ssh", "id_ecdsa"), 0644, "")
+			writeFixture(t, filepath.Join(home, "fixtures", "keys", ".ssh", "id_ed25519"), 0644, "")
+			writeFixture(t, filepath.Join(home, IgnoreFilename), 0644, tc.ignore)
+			warnings := scanWarnings(t, NewScannerWithHome(false, home), home)
+
+			for _, rel := range tc.ignored {
+				if got := warningsFor(warnings, filepath.Join(home, filepath.FromSlash(rel))); len(got) != 0 {
+					t.Errorf("%s: expected no warnings, got %v", rel, got)
+				}
+			}
+
+			for _, rel := range tc.scanned {
+				if got := warningsFor(warnings, filepath.Join(home, filepath.FromSlash(rel))); len(got) == 0 {
+					t.Errorf("%s: expected warnings, got none", rel)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		pth     string
+		match   bool
+	}{
+		{"**/fixtures", "fixtures", true},
+		{"**/fixtures", "a/b/fixtures", true},
+		{"fixtures/**", "fixtures", false},
+		{"fixtures/**", "fixtures/id_rsa", true},
+		{"fixtures/**", "fixtures/.ssh/id_rsa", true},
+		{"fixtures/**/id_rsa", "fixtures/id_rsa", true},
+		{"fixtures/**/id_rsa", "fixtures/.ssh/id_rsa", true},
+		{"fixtures/*", "fixtures/.ssh/id_rsa", false},
+	} {
+		if got := matchGlob(tc.pattern, tc.pth); got != tc.match {
+			t.Errorf("matchGlob(%q, %q): expected %v, got %v", tc.pattern, tc.pth, tc.match, got)
+		}
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		names   []string
+		match   bool
+	}{
+		{"fixtures/README.md", []string{"fixtures"}, true},
+		{"fixtures/README.md", []string{"build"}, false},
+		{"fixtures/README.md", []string{"fixtures", "README.md"}, false},
+		{"**/README.md", []string{"build"}, false},
+		{"fixtures/*/keep", []string{"fixtures", "a"}, true},
+		{"fixtures/**/keep", []string{"fixtures", "a", "b"}, true},
+		{"fixtures/**", []string{"fixtures"}, true},
+	} {
+		if got := matchPrefix(strings.Split(tc.pattern, "/"), tc.names); got != tc.match {
+			t.Errorf("matchPrefix(%q, %q): expected %v, got %v", tc.pattern, tc.names, tc.match, got)
+		}
+	}
+}
+
+func TestReincludable(t *testing.T) {
+	for _, tc := range []struct {
+		ignore       string
+		reincludable bool
+	}{
+		{"foo/\n", false},
+		{"foo/\n!bar\n", false},
+		{"foo/\n!**/bar\n", false},
+		{"foo/\n!baz/bar\n", false},
+		{"foo/\n!foo/bar\n", true},
+		{"foo/\n!foo/**/bar\n", true},
+	} {
+		home := t.TempDir()
+		writeFixture(t, filepath.Join(home, "foo", "bar"), 0644, "")
+		writeFixture(t, filepath.Join(home, IgnoreFilename), 0644, tc.ignore)
+		scanner := NewScannerWithHome(false, home)
+
+		if err := scanner.loadIgnoreFile(home); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := scanner.reincludable(filepath.Join(home, "foo"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != tc.reincludable {
+			t.Errorf("%q: expected reincludable %v, got %v", tc.ignore, tc.reincludable, got)
+		}
+	}
+}
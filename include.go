@@ -0,0 +1,157 @@
+package sunshine
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth limits nested SSH config Include directives, matching OpenSSH.
+const maxIncludeDepth = 16
+
+// ParseIncludes extracts the arguments of Include directives from SSH config content.
+func ParseIncludes(r io.Reader) ([]string, error) {
+	var includes []string
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, rest, ok := cutKeyword(line)
+
+		if !ok || !strings.EqualFold(keyword, "Include") {
+			continue
+		}
+
+		includes = append(includes, splitArguments(rest)...)
+	}
+
+	return includes, scanner.Err()
+}
+
+// cutKeyword splits an SSH config line into its keyword and arguments,
+// which are separated by whitespace and/or a single equals sign.
+func cutKeyword(line string) (string, string, bool) {
+	i := strings.IndexAny(line, " \t=")
+
+	if i < 0 {
+		return line, "", false
+	}
+
+	keyword := line[:i]
+	rest := strings.TrimLeft(line[i:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+	return keyword, strings.TrimSpace(rest), true
+}
+
+// splitArguments splits whitespace separated SSH config arguments,
+// honoring double quotes.
+func splitArguments(s string) []string {
+	var args []string
+	var arg strings.Builder
+	quoted := false
+	pending := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			pending = true
+		case (r == ' ' || r == '\t') && !quoted:
+			if pending {
+				args = append(args, arg.String())
+				arg.Reset()
+				pending = false
+			}
+		default:
+			arg.WriteRune(r)
+			pending = true
+		}
+	}
+
+	if pending {
+		args = append(args, arg.String())
+	}
+
+	return args
+}
+
+// ScanSSHIncludes analyzes the files referenced by Include directives
+// in .ssh/config files, recursively.
+//
+// Relative Include paths resolve against the directory of the config file,
+// conventionally ~/.ssh. Missing targets are skipped.
+func (o Scanner) ScanSSHIncludes(pth string, info os.FileInfo) {
+	if info.Name() != "config" || !info.Mode().IsRegular() || !o.inSSHDir(pth) {
+		return
+	}
+
+	o.followIncludes(pth, filepath.Dir(pth), map[string]bool{pth: true}, 0)
+}
+
+// followIncludes validates the Include targets of the given SSH config file.
+func (o Scanner) followIncludes(pth string, sshDir string, visited map[string]bool, depth int) {
+	if depth >= maxIncludeDepth {
+		return
+	}
+
+	f, err := o.open(pth)
+
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			o.ErrCh <- err
+		}
+
+		return
+	}
+
+	includes, err := ParseIncludes(f)
+	_ = f.Close()
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	for _, include := range includes {
+		if include == "~" || strings.HasPrefix(include, "~/") {
+			include = filepath.Join(o.homeOf(sshDir), include[1:])
+		} else if !filepath.IsAbs(include) {
+			include = filepath.Join(sshDir, include)
+		}
+
+		matches, err2 := o.glob(include)
+
+		if err2 != nil {
+			o.ErrCh <- err2
+			continue
+		}
+
+		for _, match := range matches {
+			if visited[match] {
+				continue
+			}
+
+			visited[match] = true
+			matchInfo, err3 := o.stat(match)
+
+			if err3 != nil {
+				continue
+			}
+
+			o.ValidateFile(KindSSHConfig, match, matchInfo)
+			o.ValidateChmodMax(KindSSHConfig, match, matchInfo, sshConfigMaxMode)
+
+			if matchInfo.Mode().IsRegular() {
+				o.followIncludes(match, sshDir, visited, depth+1)
+			}
+		}
+	}
+}
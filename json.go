@@ -0,0 +1,88 @@
+package sunshine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// jsonWarning models a warning in JSON reports.
+type jsonWarning struct {
+	Path     string `json:"path"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// ReportJSON renders warnings to the given writer
+// as a JSON array, at the end of the scan, in stable order.
+//
+// Each warning carries its path, its kind as the rule, severity, and message,
+// along with its observed and expected chmod bits in octal, where known.
+// Errors are rendered to the standard logger as they occur. Debug events and passes are discarded.
+//
+// Returns a nonzero exit code when any errors,
+// or any warnings of at least FailSeverity, occurred.
+func (o *Scanner) ReportJSON(w io.Writer) int {
+	status := 0
+	var warnings []Warning
+
+	for {
+		select {
+		case <-o.DebugCh:
+		case <-o.PassCh:
+		case warning := <-o.WarnCh:
+			if warning.Severity >= o.FailSeverity {
+				status = 1
+			}
+
+			warnings = append(warnings, warning)
+		case err := <-o.ErrCh:
+			status = 1
+			log.Printf("error: %s\n", err)
+		case <-o.DoneCh:
+			SortWarnings(warnings)
+
+			if o.Dedupe {
+				warnings = DedupeWarnings(warnings)
+			}
+
+			if err := writeJSON(w, warnings); err != nil {
+				return 1
+			}
+
+			return status
+		}
+	}
+}
+
+// writeJSON renders warnings as a JSON array.
+func writeJSON(w io.Writer, warnings []Warning) error {
+	results := []jsonWarning{}
+
+	for _, warning := range warnings {
+		result := jsonWarning{
+			Path:     warning.Path,
+			Rule:     string(warning.Kind),
+			Severity: warning.Severity.String(),
+			Message:  warning.Message,
+		}
+
+		if warning.Observed {
+			result.Actual = fmt.Sprintf("%04o", octal(warning.Actual))
+		}
+
+		if warning.Expected != warning.Actual {
+			result.Expected = fmt.Sprintf("%04o", octal(warning.Expected))
+		}
+
+		results = append(results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
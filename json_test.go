@@ -0,0 +1,63 @@
+package sunshine
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSONActual(t *testing.T) {
+	warnings := []Warning{
+		{Kind: KindInvisible, Severity: SeverityInfo, Path: "a", Message: "unreadable", Observed: true, Actual: 0, Expected: 0400},
+		{Kind: KindSSHMissing, Severity: SeverityInfo, Path: "b", Message: "missing, expected file"},
+		{Kind: KindSSHKey, Severity: SeverityError, Path: "c", Message: "exposed", Observed: true, Actual: 0644, Expected: 0600},
+	}
+
+	var buf bytes.Buffer
+
+	if err := writeJSON(&buf, warnings); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []map[string]string
+
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []map[string]string{
+		{"actual": "0000", "expected": "0400"},
+		{},
+		{"actual": "0644", "expected": "0600"},
+	} {
+		for _, key := range []string{"actual", "expected"} {
+			value, ok := results[i][key]
+
+			if expected[key] == "" && ok {
+				t.Errorf("%s: expected no %s, got %q", warnings[i].Path, key, value)
+			} else if value != expected[key] {
+				t.Errorf("%s: expected %s %q, got %q", warnings[i].Path, key, expected[key], value)
+			}
+		}
+	}
+
+	pth := filepath.Join(t.TempDir(), "baseline.json")
+
+	if err := os.WriteFile(pth, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := LoadBaseline(pth)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, warning := range warnings {
+		if !baseline[warning] {
+			t.Errorf("%s: expected baseline to round trip %#v", warning.Path, warning)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !plan9
+
+package sunshine
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isSymlinkLoop reports whether a stat error stems from a symlink cycle.
+func isSymlinkLoop(err error) bool {
+	return errors.Is(err, syscall.ELOOP)
+}
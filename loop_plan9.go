@@ -0,0 +1,10 @@
+//go:build plan9
+
+package sunshine
+
+// isSymlinkLoop reports whether a stat error stems from a symlink cycle.
+//
+// Plan 9 lacks symlinks.
+func isSymlinkLoop(_ error) bool {
+	return false
+}
@@ -29,6 +29,9 @@ func Audit() error {
 	return Snyk()
 }
 
+// UnitTest executes the unit test suite.
+func UnitTest() error { return mageextras.UnitTest("./...") }
+
 // Test executes the integration test suite.
 func Test() error {
 	mg.Deps(Install)
@@ -0,0 +1,24 @@
+package sunshine
+
+import (
+	"syscall"
+)
+
+// nonUNIXFilesystems enumerates statfs magic numbers for file systems
+// that synthesize mode bits rather than store them.
+var nonUNIXFilesystems = map[int64]bool{
+	0x4d44:     true, // msdos, vfat
+	0x2011bab0: true, // exfat
+	0x5346544e: true, // ntfs3
+}
+
+// modesSupported reports whether the file system holding the given path stores UNIX permissions.
+func modesSupported(pth string) bool {
+	var stat syscall.Statfs_t
+
+	if err := syscall.Statfs(pth, &stat); err != nil {
+		return true
+	}
+
+	return !nonUNIXFilesystems[int64(stat.Type)]
+}
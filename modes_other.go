@@ -0,0 +1,14 @@
+//go:build !linux
+
+package sunshine
+
+import (
+	"runtime"
+)
+
+// modesSupported reports whether the file system holding the given path stores UNIX permissions.
+//
+// Native Windows synthesizes mode bits from file attributes.
+func modesSupported(_ string) bool {
+	return runtime.GOOS != "windows"
+}
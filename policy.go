@@ -0,0 +1,628 @@
+package sunshine
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Policy describes the permissions expected of paths matching a filename based rule.
+//
+// Checks beyond filename based rules, such as those of .ssh ancestors,
+// special bits, private key contents, and ownership, are not expressed as policies.
+type Policy struct {
+	// Kind classifies warnings raised under the policy.
+	Kind Kind
+
+	// Pattern describes the governed paths, in glob notation,
+	// with ~ denoting the home directory.
+	Pattern string
+
+	// Directory denotes policies expecting directories, rather than files.
+	Directory bool
+
+	// Mode denotes the expected permission bits.
+	Mode os.FileMode
+
+	// Ceiling denotes policies accepting modes stricter than Mode.
+	Ceiling bool
+
+	// Secret denotes private key policies,
+	// reporting group or other read access as exposure, per ValidatePrivateKey.
+	Secret bool
+
+	// Subject optionally names the material governed, prefixing warning messages.
+	Subject string
+
+	// match reports whether the policy governs a path.
+	match func(o Scanner, pth string, info os.FileInfo) bool
+}
+
+// Matches reports whether the policy governs the given path, per the given scanner's settings.
+func (o Policy) Matches(scanner *Scanner, pth string, info os.FileInfo) bool {
+	return o.match(*scanner, pth, info)
+}
+
+// sshConfigMaxMode bounds SSH client configuration file permissions.
+//
+// As ssh rejects only configuration files writable by group or other,
+// any read access is accepted.
+const sshConfigMaxMode os.FileMode = 0755
+
+// parentNamed reports whether a path resides directly in a directory of the given basename.
+func parentNamed(pth string, name string) bool {
+	return filepath.Base(filepath.Dir(pth)) == name
+}
+
+// policies drives the filename based checks.
+var policies = []Policy{
+	{
+		Kind:      KindHome,
+		Pattern:   "~",
+		Directory: true,
+		Mode:      0755,
+		Ceiling:   true,
+		match: func(o Scanner, pth string, _ os.FileInfo) bool {
+			return o.isHome(pth)
+		},
+	},
+	{
+		Kind:      KindEtcSSH,
+		Pattern:   "/etc",
+		Directory: true,
+		Mode:      0755,
+		match: func(_ Scanner, pth string, _ os.FileInfo) bool {
+			return pth == "/etc"
+		},
+	},
+	{
+		Kind:      KindEtcSSH,
+		Pattern:   "/etc/ssh",
+		Directory: true,
+		Mode:      0755,
+		match: func(_ Scanner, pth string, _ os.FileInfo) bool {
+			return pth == "/etc/ssh"
+		},
+	},
+	{
+		Kind:    KindEtcSSH,
+		Pattern: "/etc/ssh/ssh_host_*_key",
+		Mode:    0600,
+		Ceiling: true,
+		Secret:  true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			return filepath.Dir(pth) == "/etc/ssh" && SSHHostKeyPattern.MatchString(name) && !SSHPublicKeyPattern.MatchString(name)
+		},
+	},
+	{
+		Kind:    KindEtcSSH,
+		Pattern: "/etc/ssh/ssh_host_*_key.pub",
+		Mode:    0644,
+		Ceiling: true,
+		Subject: "host public key",
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			return filepath.Dir(pth) == "/etc/ssh" && SSHHostKeyPattern.MatchString(name) && SSHPublicKeyPattern.MatchString(name)
+		},
+	},
+	{
+		Kind:    KindEtcSSH,
+		Pattern: "/etc/ssh/{sshd_config,ssh_config,moduli}",
+		Mode:    0644,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			return filepath.Dir(pth) == "/etc/ssh" && (name == "sshd_config" || name == "ssh_config" || name == "moduli")
+		},
+	},
+	{
+		Kind:      KindEtcSSH,
+		Pattern:   "/etc/ssh/{sshd_config.d,ssh_config.d}",
+		Directory: true,
+		Mode:      0755,
+		Ceiling:   true,
+		match: func(_ Scanner, pth string, _ os.FileInfo) bool {
+			return pth == "/etc/ssh/sshd_config.d" || pth == "/etc/ssh/ssh_config.d"
+		},
+	},
+	{
+		Kind:    KindEtcSSH,
+		Pattern: "/etc/ssh/{sshd_config.d,ssh_config.d}/*",
+		Mode:    0644,
+		Ceiling: true,
+		Subject: "configuration drop-in",
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			dir := filepath.Dir(pth)
+			return (dir == "/etc/ssh/sshd_config.d" || dir == "/etc/ssh/ssh_config.d") && !info.IsDir()
+		},
+	},
+	{
+		Kind:      KindSSHDir,
+		Pattern:   "**/.ssh",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			return info.Name() == ".ssh"
+		},
+	},
+	{
+		Kind:      KindSSHDir,
+		Pattern:   "**/.ssh/*/",
+		Directory: true,
+		Mode:      0700,
+		Subject:   "directory nested in .ssh",
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.IsDir() && parentNamed(pth, ".ssh")
+		},
+	},
+	{
+		Kind:    KindSSHConfig,
+		Pattern: "**/.ssh/config",
+		Mode:    sshConfigMaxMode,
+		Ceiling: true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "config" && o.inSSHDir(pth)
+		},
+	},
+	{
+		Kind:    KindSSHConfig,
+		Pattern: "**/.ssh/config.d/*",
+		Mode:    sshConfigMaxMode,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, _ os.FileInfo) bool {
+			return inSSHConfigDir(pth)
+		},
+	},
+	{
+		Kind:    KindSSHKey,
+		Pattern: "**/.ssh/*.pub",
+		Mode:    0644,
+		Ceiling: true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			key, public := o.IsSSHKey(info.Name())
+			return key && public && o.inSSHDir(pth)
+		},
+	},
+	{
+		Kind:    KindSSHKey,
+		Pattern: "**/.ssh/id_* (or KeyPatterns)",
+		Mode:    0600,
+		Ceiling: true,
+		Secret:  true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			key, public := o.IsSSHKey(info.Name())
+			return key && !public && o.inSSHDir(pth)
+		},
+	},
+	{
+		Kind:    KindSSHAuthorizedKeys,
+		Pattern: "**/{authorized_keys,authorized_keys2}",
+		Mode:    0600,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			name := info.Name()
+			return name == "authorized_keys" || name == "authorized_keys2"
+		},
+	},
+	{
+		Kind:    KindSSHKnownHosts,
+		Pattern: "**/{known_hosts,known_hosts2}",
+		Mode:    0644,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			name := info.Name()
+			return name == "known_hosts" || name == "known_hosts2"
+		},
+	},
+	{
+		Kind:    KindSSHEnvironment,
+		Pattern: "**/.ssh/environment",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "environment" && parentNamed(pth, ".ssh")
+		},
+	},
+	{
+		Kind:      KindGnuPG,
+		Pattern:   "**/.gnupg",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			return info.Name() == ".gnupg"
+		},
+	},
+	{
+		Kind:      KindGnuPG,
+		Pattern:   "**/.gnupg/private-keys-v1.d",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "private-keys-v1.d" && parentNamed(pth, ".gnupg")
+		},
+	},
+	{
+		Kind:    KindGnuPG,
+		Pattern: "**/.gnupg/{pubring.kbx,pubring.gpg,secring.gpg,trustdb.gpg}",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return parentNamed(pth, ".gnupg") && GnuPGKeyringPattern.MatchString(info.Name())
+		},
+	},
+	{
+		Kind:    KindGnuPG,
+		Pattern: "**/.gnupg/{gpg,gpg-agent,dirmngr,scdaemon,common}.conf",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return parentNamed(pth, ".gnupg") && GnuPGConfigPattern.MatchString(info.Name())
+		},
+	},
+	{
+		Kind:    KindGnuPG,
+		Pattern: "**/.gnupg/private-keys-v1.d/*",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return parentNamed(pth, "private-keys-v1.d") && parentNamed(filepath.Dir(pth), ".gnupg") && info.Mode().IsRegular()
+		},
+	},
+	{
+		Kind:      KindAWS,
+		Pattern:   "**/.aws",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			return info.Name() == ".aws"
+		},
+	},
+	{
+		Kind:    KindAWS,
+		Pattern: "**/.aws/{credentials,config}",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			return (name == "credentials" || name == "config") && parentNamed(pth, ".aws")
+		},
+	},
+	{
+		Kind:      KindAzure,
+		Pattern:   "**/.azure",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			return info.Name() == ".azure"
+		},
+	},
+	{
+		Kind:    KindAzure,
+		Pattern: "**/.azure/{accessTokens.json,msal_token_cache.*,service_principal_entries.*}",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return parentNamed(pth, ".azure") && AzureTokenPattern.MatchString(info.Name())
+		},
+	},
+	{
+		Kind:    KindGCloud,
+		Pattern: "**/.config/gcloud/{credentials.db,access_tokens.db,application_default_credentials.json}",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return parentNamed(pth, "gcloud") && parentNamed(filepath.Dir(pth), ".config") && GCloudCredentialPattern.MatchString(info.Name())
+		},
+	},
+	{
+		Kind:    KindGCloud,
+		Pattern: "**/.config/gcloud/legacy_credentials/*/{adc.json,.boto}",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			accountDir := filepath.Dir(pth)
+			return (name == "adc.json" || name == ".boto") && parentNamed(accountDir, "legacy_credentials") && parentNamed(filepath.Dir(accountDir), "gcloud")
+		},
+	},
+	{
+		Kind:      KindDocker,
+		Pattern:   "**/.docker",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			return info.Name() == ".docker"
+		},
+	},
+	{
+		Kind:    KindDocker,
+		Pattern: "**/.docker/config.json",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "config.json" && parentNamed(pth, ".docker")
+		},
+	},
+	{
+		Kind:    KindDocker,
+		Pattern: "**/containers/auth.json",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "container registry credentials",
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "auth.json" && parentNamed(pth, "containers")
+		},
+	},
+	{
+		Kind:      KindKube,
+		Pattern:   "**/.kube",
+		Directory: true,
+		Mode:      0700,
+		match: func(_ Scanner, _ string, info os.FileInfo) bool {
+			return info.Name() == ".kube"
+		},
+	},
+	{
+		Kind:    KindKube,
+		Pattern: "**/.kube/config",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "config" && parentNamed(pth, ".kube")
+		},
+	},
+	{
+		Kind:    KindKube,
+		Pattern: "$KUBECONFIG (per KubeConfigs)",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return !info.IsDir() && o.isKubeConfig(pth) && !(info.Name() == "config" && parentNamed(pth, ".kube"))
+		},
+	},
+	{
+		Kind:    KindNetrc,
+		Pattern: "~/{.netrc,_netrc,.authinfo,.authinfo.gpg}",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return NetrcPattern.MatchString(info.Name()) && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindGitCredentials,
+		Pattern: "~/.git-credentials",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == ".git-credentials" && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindGitCredentials,
+		Pattern: "~/.config/git/credentials",
+		Mode:    0600,
+		Ceiling: true,
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			if info.Name() != "credentials" {
+				return false
+			}
+
+			absPath, err := filepath.Abs(pth)
+			return err == nil && filepath.Dir(absPath) == filepath.Join(o.homeOf(absPath), ".config", "git")
+		},
+	},
+	{
+		Kind:    KindPgpass,
+		Pattern: "~/.pgpass",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "PostgreSQL password file, ignored by psql and other libpq clients while accessible to group or other",
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == ".pgpass" && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindPgpass,
+		Pattern: "~/.pg_service.conf",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "PostgreSQL connection service file, whose passwords libpq clients read regardless of permissions",
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == ".pg_service.conf" && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindMySQL,
+		Pattern: "~/.my.cnf",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "MySQL option file, whose passwords mysql clients read unless world-writable",
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == ".my.cnf" && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindMySQL,
+		Pattern: "~/.mylogin.cnf",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "MySQL login path file, ignored by mysql clients while accessible to group or other",
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == ".mylogin.cnf" && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindPublishToken,
+		Pattern: "~/{.npmrc,.pypirc}",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "package registry credentials",
+		match: func(o Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			return (name == ".npmrc" || name == ".pypirc") && o.inHome(pth)
+		},
+	},
+	{
+		Kind:    KindPublishToken,
+		Pattern: "**/.cargo/credentials{,.toml}",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "crates.io token file",
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			name := info.Name()
+			return (name == "credentials" || name == "credentials.toml") && parentNamed(pth, ".cargo")
+		},
+	},
+	{
+		Kind:    KindPublishToken,
+		Pattern: "**/.gem/credentials",
+		Mode:    0600,
+		Ceiling: true,
+		Subject: "RubyGems API key file, rejected by gem push unless chmod 0600",
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			return info.Name() == "credentials" && parentNamed(pth, ".gem")
+		},
+	},
+}
+
+// policiesByKind indexes policies by kind, in table order.
+var policiesByKind = func() map[Kind][]Policy {
+	byKind := make(map[Kind][]Policy)
+
+	for _, policy := range policies {
+		byKind[policy.Kind] = append(byKind[policy.Kind], policy)
+	}
+
+	return byKind
+}()
+
+// Policies enumerates the filename based policies driving the built-in checks.
+func Policies() []Policy {
+	return append([]Policy(nil), policies...)
+}
+
+// Enforce applies a policy to a path, regardless of whether the policy matches the path.
+func (o *Scanner) Enforce(policy Policy, pth string, info os.FileInfo) {
+	if policy.Directory {
+		o.ValidateDirectory(policy.Kind, pth, info)
+	} else {
+		o.ValidateFile(policy.Kind, pth, info)
+	}
+
+	switch {
+	case policy.Secret:
+		o.ValidatePrivateKey(policy.Kind, pth, info)
+	case policy.Ceiling:
+		o.validateChmodMax(policy.Kind, pth, info, policy.Mode, policy.Subject)
+	default:
+		o.validateChmod(policy.Kind, pth, info, policy.Mode, policy.Subject)
+	}
+}
+
+// enforcePolicies applies the policies of the given kinds matching a path.
+func (o *Scanner) enforcePolicies(pth string, info os.FileInfo, kinds ...Kind) {
+	for _, kind := range kinds {
+		for _, policy := range policiesByKind[kind] {
+			if policy.match(*o, pth, info) {
+				o.Enforce(policy, pth, info)
+			}
+		}
+	}
+}
+
+// AddRule appends a user-defined policy, applied to each walked path after the built-in checks,
+// warning of KindCustom when paths matching the given glob pattern
+// carry permission bits beyond the given mode.
+//
+// In the pattern, ** matches any number of path segments, and a trailing ** matches one or more,
+// other segments follow path.Match, and a leading ~ denotes Home.
+// Relative patterns are resolved against the working directory.
+// Patterns ending in a slash match directories only, and other patterns match files only.
+//
+// Add rules before scanning.
+func (o *Scanner) AddRule(pattern string, mode os.FileMode) error {
+	directory := strings.HasSuffix(pattern, "/")
+	expanded := strings.TrimSuffix(pattern, "/")
+
+	if expanded == "~" || strings.HasPrefix(expanded, "~/") {
+		expanded = filepath.Join(o.Home, expanded[1:])
+	}
+
+	absPattern, err := filepath.Abs(expanded)
+
+	if err != nil {
+		return err
+	}
+
+	absPattern = filepath.ToSlash(absPattern)
+
+	for _, segment := range strings.Split(absPattern, "/") {
+		if _, err2 := path.Match(segment, ""); err2 != nil {
+			return fmt.Errorf("%s: %w", pattern, err2)
+		}
+	}
+
+	policy := Policy{
+		Kind:      KindCustom,
+		Pattern:   pattern,
+		Directory: directory,
+		Mode:      mode,
+		Ceiling:   true,
+		match: func(_ Scanner, pth string, info os.FileInfo) bool {
+			if info.IsDir() != directory {
+				return false
+			}
+
+			absPath, err2 := filepath.Abs(pth)
+			return err2 == nil && matchGlob(absPattern, filepath.ToSlash(absPath))
+		},
+	}
+
+	o.RegisterCheck(func(scanner *Scanner, pth string, info os.FileInfo) {
+		if policy.Matches(scanner, pth, info) {
+			scanner.Enforce(policy, pth, info)
+		}
+	})
+
+	return nil
+}
+
+// matchGlob reports whether a slash separated path matches a validated glob pattern,
+// where ** matches any number of path segments, and a trailing ** matches one or more.
+func matchGlob(pattern string, pth string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(pth, "/"))
+}
+
+// matchSegments matches path segments against glob pattern segments.
+func matchSegments(patterns []string, names []string) bool {
+	for len(patterns) != 0 {
+		if patterns[0] == "**" {
+			// A trailing ** matches everything within a directory, but not the directory itself.
+			if len(patterns) == 1 {
+				return len(names) != 0
+			}
+
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		if match, _ := path.Match(patterns[0], names[0]); !match {
+			return false
+		}
+
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
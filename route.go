@@ -0,0 +1,122 @@
+package sunshine
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Route directs paths to a check,
+// by basename or by the names of enclosing directories,
+// sparing checks from paths they cannot concern.
+//
+// Routes lacking both Names and Within apply to every path.
+// Checks still verify their own preconditions, as routes may overmatch.
+type Route struct {
+	// Names lists basenames of paths routed to the check.
+	Names []string
+
+	// Within lists directory basenames,
+	// routing any paths nested beneath such directories to the check.
+	Within []string
+
+	// Check analyzes routed paths.
+	Check Check
+}
+
+// DefaultRoutes enumerates the built-in checks, with their routes.
+func DefaultRoutes() []Route {
+	return []Route{
+		{Check: (*Scanner).ScanInvisible},
+		{Check: (*Scanner).ScanHome},
+		{Names: []string{"etc", "ssh"}, Within: []string{"ssh"}, Check: (*Scanner).ScanEtcSSH},
+		{Names: []string{".ssh"}, Within: []string{".ssh"}, Check: (*Scanner).ScanUserSSH},
+		{Names: []string{".ssh"}, Check: (*Scanner).ScanAncestors},
+		{Names: []string{".ssh"}, Check: (*Scanner).ScanSSHMissing},
+		{Within: []string{".ssh"}, Check: (*Scanner).ScanSSHConfig},
+		{Within: []string{".ssh"}, Check: (*Scanner).ScanSSHIncludes},
+		{Within: []string{".ssh"}, Check: (*Scanner).ScanSSHKeys},
+		{Within: []string{".ssh"}, Check: (*Scanner).ScanSSHKeyContents},
+		{Within: []string{".ssh"}, Check: (*Scanner).ScanSSHKeyPassphrase},
+		{Names: []string{"authorized_keys", "authorized_keys2"}, Check: (*Scanner).ScanSSHAuthorizedKeys},
+		{Names: []string{"known_hosts", "known_hosts2"}, Check: (*Scanner).ScanSSHKnownHosts},
+		{Names: []string{"environment"}, Check: (*Scanner).ScanSSHEnvironment},
+		{Names: []string{".gnupg"}, Within: []string{".gnupg"}, Check: (*Scanner).ScanGnuPG},
+		{Names: []string{".aws"}, Within: []string{".aws"}, Check: (*Scanner).ScanAWS},
+		{Names: []string{".azure"}, Within: []string{".azure"}, Check: (*Scanner).ScanAzure},
+		{Within: []string{"gcloud"}, Check: (*Scanner).ScanGCloud},
+		{Names: []string{".docker"}, Within: []string{".docker", "containers"}, Check: (*Scanner).ScanDocker},
+		{Check: (*Scanner).ScanKube},
+		{Check: (*Scanner).ScanSpecialBits},
+		{Names: []string{".ssh"}, Within: []string{".ssh"}, Check: (*Scanner).ScanOwnership},
+		{Names: []string{".ssh"}, Within: []string{".ssh"}, Check: (*Scanner).ScanACL},
+		{Names: []string{".netrc", "_netrc", ".authinfo", ".authinfo.gpg"}, Check: (*Scanner).ScanNetrc},
+		{Names: []string{".git-credentials", "credentials"}, Check: (*Scanner).ScanGitCredentials},
+		{Names: []string{".pgpass", ".pg_service.conf", ".my.cnf", ".mylogin.cnf"}, Check: (*Scanner).ScanDatabaseCredentials},
+		{Names: []string{".npmrc", ".pypirc"}, Within: []string{".cargo", ".gem"}, Check: (*Scanner).ScanPublishTokens},
+		{Check: (*Scanner).ScanWorldWritable},
+	}
+}
+
+// RegisterRoute appends a custom check, applied to the paths it routes,
+// after the built-in checks.
+//
+// Register routes before scanning.
+func (o *Scanner) RegisterRoute(route Route) {
+	if o.byName == nil {
+		o.byName = make(map[string][]int)
+	}
+
+	if o.byWithin == nil {
+		o.byWithin = make(map[string][]int)
+	}
+
+	i := len(o.routes)
+	o.routes = append(o.routes, route)
+
+	if len(route.Names) == 0 && len(route.Within) == 0 {
+		o.general = append(o.general, i)
+		return
+	}
+
+	for _, name := range route.Names {
+		o.byName[name] = append(o.byName[name], i)
+	}
+
+	for _, dir := range route.Within {
+		o.byWithin[dir] = append(o.byWithin[dir], i)
+	}
+}
+
+// dispatch applies the checks routed to the given path,
+// in registration order.
+func (o *Scanner) dispatch(pth string, info os.FileInfo) {
+	routed := make([]bool, len(o.routes))
+
+	for _, i := range o.general {
+		routed[i] = true
+	}
+
+	for _, i := range o.byName[info.Name()] {
+		routed[i] = true
+	}
+
+	if len(o.byWithin) != 0 {
+		dir := filepath.Dir(pth)
+
+		if absDir, err := filepath.Abs(dir); err == nil {
+			dir = absDir
+		}
+
+		for ; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			for _, i := range o.byWithin[filepath.Base(dir)] {
+				routed[i] = true
+			}
+		}
+	}
+
+	for i, route := range o.routes {
+		if routed[i] {
+			route.Check(o, pth, info)
+		}
+	}
+}
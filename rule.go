@@ -0,0 +1,41 @@
+package sunshine
+
+import (
+	"os"
+)
+
+// Rule expresses a custom permission policy.
+type Rule interface {
+	// Match reports whether the rule concerns the given path.
+	Match(pth string, info os.FileInfo) bool
+
+	// Check analyzes a matched path, returning any discrepancies.
+	Check(pth string, info os.FileInfo) []Warning
+}
+
+// NewWarning constructs a warning at the default severity of its kind.
+func NewWarning(kind Kind, pth string, msg string) Warning {
+	return Warning{Kind: kind, Severity: kind.Severity(), Path: pth, Message: msg}
+}
+
+// RegisterRule appends a custom rule,
+// applied to each walked path after the built-in checks.
+//
+// The built-in checks remain routes rather than rules,
+// as they stream passes and share per-scan state such as discovered SSH keys.
+//
+// Warnings are signaled as given, such that rules set their own severities, as by NewWarning,
+// subject to the same severity filtering, deduplication, and reporting as the built-in checks.
+//
+// Register rules before scanning.
+func (o *Scanner) RegisterRule(rule Rule) {
+	o.RegisterCheck(func(scanner *Scanner, pth string, info os.FileInfo) {
+		if !rule.Match(pth, info) {
+			return
+		}
+
+		for _, warning := range rule.Check(pth, info) {
+			scanner.signal(warning)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package sunshine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// envRule flags .env files readable beyond their owner.
+type envRule struct{}
+
+func (envRule) Match(pth string, info os.FileInfo) bool {
+	return filepath.Base(pth) == ".env" && info.Mode().IsRegular()
+}
+
+func (envRule) Check(pth string, info os.FileInfo) []Warning {
+	if info.Mode().Perm()&0077 == 0 {
+		return nil
+	}
+
+	warning := NewWarning("env", pth, "readable beyond owner")
+	warning.Severity = SeverityError
+	return []Warning{warning}
+}
+
+func TestRegister(t *testing.T) {
+	root := t.TempDir()
+	env := filepath.Join(root, "project", ".env")
+	readme := filepath.Join(root, "project", "README.md")
+	writeFixture(t, env, 0644, "")
+	writeFixture(t, readme, 0644, "")
+
+	scanner := NewScannerWithHome(false, root)
+	scanner.Register("todo", func(pth string, _ os.FileInfo) []string {
+		if strings.HasSuffix(pth, ".md") {
+			return []string{"documentation"}
+		}
+
+		return nil
+	})
+	scanner.RegisterRule(envRule{})
+	warnings := scanWarnings(t, scanner, root)
+
+	for _, tc := range []struct {
+		pth      string
+		kind     Kind
+		severity Severity
+	}{
+		{readme, "todo", SeverityWarning},
+		{env, "env", SeverityError},
+	} {
+		matched := warningsFor(warnings, tc.pth)
+
+		if len(matched) != 1 {
+			t.Errorf("%s: expected one warning, got %v", tc.pth, matched)
+			continue
+		}
+
+		if matched[0].Kind != tc.kind || matched[0].Severity != tc.severity {
+			t.Errorf("%s: expected %s %s, got %v", tc.pth, tc.severity, tc.kind, matched[0])
+		}
+	}
+}
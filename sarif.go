@@ -0,0 +1,250 @@
+package sunshine
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SARIFVersion denotes the SARIF specification version rendered by ReportSARIF.
+const SARIFVersion = "2.1.0"
+
+// SARIFSchema locates the SARIF JSON schema.
+const SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifRootBaseID names the base URI of scan root relative artifact locations.
+const sarifRootBaseID = "ROOT"
+
+// sarifLevels maps severities to SARIF result levels.
+var sarifLevels = map[Severity]string{
+	SeverityInfo:    "note",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+// sarifLog models a minimal SARIF document.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun models a SARIF run.
+type sarifRun struct {
+	Tool               sarifTool                `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifact `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult            `json:"results"`
+	Invocations        []sarifInvocation        `json:"invocations"`
+}
+
+// sarifTool models a SARIF tool.
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver models a SARIF tool component.
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule models a SARIF reporting descriptor.
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     *sarifMessage      `json:"shortDescription,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+// sarifConfiguration models a SARIF reporting configuration.
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// sarifMessage models a SARIF message.
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifArtifact models a SARIF artifact location.
+type sarifArtifact struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifLocation models a SARIF location.
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+// sarifResult models a SARIF result.
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// sarifNotification models a SARIF notification.
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+// sarifInvocation models a SARIF invocation.
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+// ReportSARIF renders scan events to the given writer
+// as a SARIF document, until the end of the scan.
+//
+// Each warning becomes a result, with its kind as the rule ID.
+// Rules describe each kind reported, along with its default severity.
+// Artifact locations are relative to the given root where possible.
+// Errors become tool execution notifications. Debug events and passes are discarded.
+//
+// Returns a nonzero exit code when any errors,
+// or any warnings of at least FailSeverity, occurred.
+func (o *Scanner) ReportSARIF(root string, w io.Writer) int {
+	status := 0
+	var warnings []Warning
+	var notifications []sarifNotification
+
+	for {
+		select {
+		case <-o.DebugCh:
+		case <-o.PassCh:
+		case warning := <-o.WarnCh:
+			if warning.Severity >= o.FailSeverity {
+				status = 1
+			}
+
+			// Artifact locations are relative to the root, rather than to BasePath.
+			if o.BasePath != "" && !filepath.IsAbs(warning.Path) {
+				warning.Path = filepath.Join(o.BasePath, warning.Path)
+			}
+
+			warnings = append(warnings, warning)
+		case err := <-o.ErrCh:
+			status = 1
+			notifications = append(notifications, sarifNotification{Level: "error", Message: sarifMessage{Text: err.Error()}})
+		case <-o.DoneCh:
+			if o.Dedupe {
+				SortWarnings(warnings)
+				warnings = DedupeWarnings(warnings)
+			}
+
+			if err := writeSARIF(root, w, warnings, notifications); err != nil {
+				return 1
+			}
+
+			return status
+		}
+	}
+}
+
+// writeSARIF renders warnings and notifications as a SARIF document.
+func writeSARIF(root string, w io.Writer, warnings []Warning, notifications []sarifNotification) error {
+	SortWarnings(warnings)
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "sunshine",
+				Version:        Version,
+				InformationURI: "https://github.com/mcandre/sunshine",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+		Invocations: []sarifInvocation{
+			{
+				ExecutionSuccessful:        len(notifications) == 0,
+				ToolExecutionNotifications: notifications,
+			},
+		},
+	}
+
+	absRoot, err := filepath.Abs(root)
+
+	if err == nil {
+		run.OriginalURIBaseIDs = map[string]sarifArtifact{
+			sarifRootBaseID: {URI: strings.TrimSuffix(fileURI(absRoot), "/") + "/"},
+		}
+	}
+
+	kinds := make(map[Kind]bool)
+
+	for _, warning := range warnings {
+		kinds[warning.Kind] = true
+
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation = sarifArtifactFor(absRoot, warning.Path)
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    string(warning.Kind),
+			Level:     sarifLevels[warning.Severity],
+			Message:   sarifMessage{Text: warning.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	for kind := range kinds {
+		rule := sarifRule{
+			ID:                   string(kind),
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevels[kind.Severity()]},
+		}
+
+		if description := kind.Description(); description != "" {
+			rule.ShortDescription = &sarifMessage{Text: description}
+		}
+
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	}
+
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: []sarifRun{run}})
+}
+
+// sarifArtifactFor locates a path relative to the scan root,
+// or absolutely when the path lies outside of the root.
+func sarifArtifactFor(absRoot string, pth string) sarifArtifact {
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return sarifArtifact{URI: (&url.URL{Path: filepath.ToSlash(pth)}).String()}
+	}
+
+	if absRoot != "" && within(absRoot, absPath) {
+		if rel, err2 := filepath.Rel(absRoot, absPath); err2 == nil {
+			return sarifArtifact{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: sarifRootBaseID}
+		}
+	}
+
+	return sarifArtifact{URI: fileURI(absPath)}
+}
+
+// fileURI renders an absolute path as a file URI.
+func fileURI(absPath string) string {
+	pth := filepath.ToSlash(absPath)
+
+	if filepath.VolumeName(absPath) != "" {
+		pth = "/" + pth
+	}
+
+	return (&url.URL{Scheme: "file", Path: pth}).String()
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package sunshine
+
+// securityDescriptorsSupported indicates that file access is governed by security descriptors,
+// rather than by chmod bits.
+const securityDescriptorsSupported = false
+
+// descriptorDiscrepancies evaluates the owner and DACL of the given path
+// as OpenSSH for Windows does.
+//
+// Security descriptors are evaluated on Windows only.
+func descriptorDiscrepancies(_ string, _ string, _ bool) ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,249 @@
+//go:build windows
+
+package sunshine
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// securityDescriptorsSupported indicates that file access is governed by security descriptors,
+// rather than by chmod bits.
+const securityDescriptorsSupported = true
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procGetNamedSecurityInfoW = advapi32.NewProc("GetNamedSecurityInfoW")
+	procGetAce                = advapi32.NewProc("GetAce")
+)
+
+const (
+	// seFileObject denotes SE_FILE_OBJECT.
+	seFileObject = 1
+
+	// ownerSecurityInformation denotes OWNER_SECURITY_INFORMATION.
+	ownerSecurityInformation = 0x00000001
+
+	// daclSecurityInformation denotes DACL_SECURITY_INFORMATION.
+	daclSecurityInformation = 0x00000004
+
+	// accessAllowedACEType denotes ACCESS_ALLOWED_ACE_TYPE.
+	accessAllowedACEType = 0
+
+	// inheritOnlyACE denotes INHERIT_ONLY_ACE, for ACEs not applying to the object itself.
+	inheritOnlyACE = 0x08
+
+	// readAccessMask unions FILE_READ_DATA, GENERIC_ALL, and GENERIC_READ.
+	readAccessMask = 0x00000001 | 0x10000000 | 0x80000000
+
+	// writeAccessMask unions FILE_WRITE_DATA, FILE_APPEND_DATA, FILE_WRITE_EA, FILE_WRITE_ATTRIBUTES,
+	// GENERIC_ALL, and GENERIC_WRITE.
+	writeAccessMask = 0x00000002 | 0x00000004 | 0x00000010 | 0x00000100 | 0x10000000 | 0x40000000
+)
+
+// trustedSIDs enumerates the well-known SYSTEM and Administrators SIDs,
+// which OpenSSH for Windows permits to own and access sensitive files, besides the user.
+var trustedSIDs = map[string]bool{
+	"S-1-5-18":     true,
+	"S-1-5-32-544": true,
+}
+
+// acl models the ACL header.
+type acl struct {
+	AclRevision byte
+	Sbz1        byte
+	AclSize     uint16
+	AceCount    uint16
+	Sbz2        uint16
+}
+
+// accessAllowedACE models ACCESS_ALLOWED_ACE.
+type accessAllowedACE struct {
+	AceType  byte
+	AceFlags byte
+	AceSize  uint16
+	Mask     uint32
+	SidStart uint32
+}
+
+// descriptorDiscrepancies evaluates the owner and DACL of the given path
+// as OpenSSH for Windows does, trusting SYSTEM, Administrators, the current user,
+// and the owner of the given home directory.
+//
+// Paths must be owned by a trusted account.
+// Allow entries granting other trustees any access are reported,
+// or, when readOK, only those granting write access.
+func descriptorDiscrepancies(pth string, home string, readOK bool) ([]string, error) {
+	trusted := make(map[string]bool)
+
+	for sid := range trustedSIDs {
+		trusted[sid] = true
+	}
+
+	user, err := currentUserSID()
+
+	if err != nil {
+		return nil, err
+	}
+
+	trusted[user] = true
+
+	homeOwner, _, sd, err := fileSecurity(home)
+
+	if err != nil {
+		return nil, err
+	}
+
+	homeOwnerSID, err := homeOwner.String()
+	_, _ = syscall.LocalFree(sd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	trusted[homeOwnerSID] = true
+
+	owner, dacl, sd, err := fileSecurity(pth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_, _ = syscall.LocalFree(sd)
+	}()
+
+	var discrepancies []string
+	ownerSID, err := owner.String()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !trusted[ownerSID] {
+		discrepancies = append(discrepancies, fmt.Sprintf("owned by %s, expected owner %s, SYSTEM, or Administrators", trusteeName(owner, ownerSID), sidName(homeOwnerSID)))
+	}
+
+	// A null DACL grants everyone full access.
+	if dacl == nil {
+		return append(discrepancies, "writable by Everyone, null DACL, expected access limited to owner, SYSTEM, and Administrators"), nil
+	}
+
+	for i := 0; i < int(dacl.AceCount); i++ {
+		var ace *accessAllowedACE
+
+		if r, _, err2 := procGetAce.Call(uintptr(unsafe.Pointer(dacl)), uintptr(i), uintptr(unsafe.Pointer(&ace))); r == 0 {
+			return nil, &os.PathError{Op: "GetAce", Path: pth, Err: err2}
+		}
+
+		if ace.AceType != accessAllowedACEType || ace.AceFlags&inheritOnlyACE != 0 {
+			continue
+		}
+
+		sid := (*syscall.SID)(unsafe.Pointer(&ace.SidStart))
+		sidString, err2 := sid.String()
+
+		if err2 != nil {
+			return nil, err2
+		}
+
+		if trusted[sidString] {
+			continue
+		}
+
+		var access string
+
+		switch {
+		case ace.Mask&writeAccessMask != 0:
+			access = "writable"
+		case readOK:
+			continue
+		case ace.Mask&readAccessMask != 0:
+			access = "readable"
+		default:
+			access = "accessible"
+		}
+
+		discrepancies = append(discrepancies, fmt.Sprintf("%s by %s, expected access limited to owner, SYSTEM, and Administrators", access, trusteeName(sid, sidString)))
+	}
+
+	return discrepancies, nil
+}
+
+// fileSecurity queries the owner and DACL of the given path,
+// along with the enclosing security descriptor, to be released with LocalFree.
+func fileSecurity(pth string) (*syscall.SID, *acl, syscall.Handle, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(pth)
+
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var owner *syscall.SID
+	var dacl *acl
+	var sd syscall.Handle
+
+	if r, _, _ := procGetNamedSecurityInfoW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		seFileObject,
+		ownerSecurityInformation|daclSecurityInformation,
+		uintptr(unsafe.Pointer(&owner)),
+		0,
+		uintptr(unsafe.Pointer(&dacl)),
+		0,
+		uintptr(unsafe.Pointer(&sd)),
+	); r != 0 {
+		return nil, nil, 0, &os.PathError{Op: "GetNamedSecurityInfo", Path: pth, Err: syscall.Errno(r)}
+	}
+
+	return owner, dacl, sd, nil
+}
+
+// currentUserSID queries the SID of the user running the scan.
+func currentUserSID() (string, error) {
+	token, err := syscall.OpenCurrentProcessToken()
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = token.Close()
+	}()
+
+	user, err := token.GetTokenUser()
+
+	if err != nil {
+		return "", err
+	}
+
+	return user.User.Sid.String()
+}
+
+// sidName renders a SID string as a DOMAIN\account name where possible.
+func sidName(sidString string) string {
+	sid, err := syscall.StringToSid(sidString)
+
+	if err != nil {
+		return sidString
+	}
+
+	return trusteeName(sid, sidString)
+}
+
+// trusteeName renders a SID as a DOMAIN\account name where possible.
+func trusteeName(sid *syscall.SID, sidString string) string {
+	account, domain, _, err := sid.LookupAccount("")
+
+	if err != nil {
+		return sidString
+	}
+
+	if domain == "" {
+		return account
+	}
+
+	return domain + `\` + account
+}
@@ -0,0 +1,92 @@
+package sunshine
+
+import (
+	"fmt"
+)
+
+// Severity ranks warnings by consequence.
+type Severity int
+
+const (
+	// SeverityInfo denotes cosmetic or advisory findings.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning denotes findings likely to break tools, or to weaken defenses.
+	SeverityWarning
+
+	// SeverityError denotes exposed secrets.
+	SeverityError
+)
+
+// String renders a severity.
+func (o Severity) String() string {
+	switch o {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(o))
+	}
+}
+
+// ParseSeverity validates a severity name.
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		if s == severity.String() {
+			return severity, nil
+		}
+	}
+
+	return SeverityInfo, fmt.Errorf("unknown severity %q, expected info, warning, or error", s)
+}
+
+// kindSeverities assigns default severities to warning kinds.
+//
+// Kinds absent here, such as those of custom checks, default to SeverityWarning.
+var kindSeverities = map[Kind]Severity{
+	KindPlatform:           SeverityInfo,
+	KindInvisible:          SeverityInfo,
+	KindWorldWritable:      SeverityInfo,
+	KindSymlink:            SeverityInfo,
+	KindSSHKeyPair:         SeverityInfo,
+	KindSSHKnownHosts:      SeverityInfo,
+	KindSSHMissing:         SeverityInfo,
+	KindGnuPG:              SeverityError,
+	KindAWS:                SeverityError,
+	KindAzure:              SeverityError,
+	KindGCloud:             SeverityError,
+	KindDocker:             SeverityError,
+	KindKube:               SeverityError,
+	KindGitCredentials:     SeverityError,
+	KindNetrc:              SeverityError,
+	KindPgpass:             SeverityError,
+	KindMySQL:              SeverityError,
+	KindPublishToken:       SeverityError,
+	KindSecurityDescriptor: SeverityError,
+	KindHome:               SeverityWarning,
+	KindEtcSSH:             SeverityWarning,
+	KindSSHDir:             SeverityWarning,
+	KindSSHAncestor:        SeverityWarning,
+	KindHomeAncestor:       SeverityWarning,
+	KindSSHConfig:          SeverityWarning,
+	KindSSHKey:             SeverityWarning,
+	KindSSHKeyPassphrase:   SeverityWarning,
+	KindSSHAuthorizedKeys:  SeverityWarning,
+	KindSSHEnvironment:     SeverityWarning,
+	KindSpecialBits:        SeverityWarning,
+	KindOwnership:          SeverityWarning,
+	KindACL:                SeverityWarning,
+	KindCustom:             SeverityWarning,
+}
+
+// Severity reports the default severity of warnings of this kind.
+func (o Kind) Severity() Severity {
+	if severity, ok := kindSeverities[o]; ok {
+		return severity
+	}
+
+	return SeverityWarning
+}
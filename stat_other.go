@@ -0,0 +1,41 @@
+//go:build !unix
+
+package sunshine
+
+import (
+	"os"
+)
+
+// fileID identifies a file by device and inode.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// owner queries the user ID owning a file.
+//
+// File ownership is unavailable on non-UNIX platforms.
+func owner(_ os.FileInfo) (int, bool) {
+	return 0, false
+}
+
+// group queries the group ID owning a file.
+//
+// File ownership is unavailable on non-UNIX platforms.
+func group(_ os.FileInfo) (int, bool) {
+	return 0, false
+}
+
+// groupNamed reports whether the given group ID resolves to the given group name.
+//
+// Groups are unavailable on non-UNIX platforms.
+func groupNamed(_ int, _ string) bool {
+	return false
+}
+
+// identify queries the device and inode of a file.
+//
+// File identity is unavailable on non-UNIX platforms.
+func identify(_ os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,55 @@
+//go:build unix
+
+package sunshine
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// fileID identifies a file by device and inode.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// owner queries the user ID owning a file.
+func owner(info os.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return 0, false
+	}
+
+	return int(stat.Uid), true
+}
+
+// group queries the group ID owning a file.
+func group(info os.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return 0, false
+	}
+
+	return int(stat.Gid), true
+}
+
+// groupNamed reports whether the given group ID resolves to the given group name.
+func groupNamed(gid int, name string) bool {
+	g, err := user.LookupGroupId(strconv.Itoa(gid))
+	return err == nil && g.Name == name
+}
+
+// identify queries the device and inode of a file.
+func identify(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return fileID{}, false
+	}
+
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
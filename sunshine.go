@@ -2,21 +2,62 @@
 package sunshine
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
 )
 
+// GnuPGKeyringPattern matches GnuPG keyring and trust database filenames.
+var GnuPGKeyringPattern = regexp.MustCompile(`^(pubring\.(kbx|gpg)|secring\.gpg|trustdb\.gpg)$`)
+
+// GnuPGConfigPattern matches GnuPG configuration filenames.
+var GnuPGConfigPattern = regexp.MustCompile(`^(gpg|gpg-agent|dirmngr|scdaemon|common)\.conf$`)
+
+// AzureTokenPattern matches Azure CLI token cache and service principal filenames.
+var AzureTokenPattern = regexp.MustCompile(`^(accessTokens\.json|msal_token_cache\.(json|bin)|service_principal_entries\.(json|bin))$`)
+
+// GCloudCredentialPattern matches Google Cloud CLI credential filenames.
+var GCloudCredentialPattern = regexp.MustCompile(`^(credentials\.db|access_tokens\.db|application_default_credentials\.json)$`)
+
+// NetrcPattern matches .netrc, _netrc, and .authinfo filenames.
+//
+// curl and git for Windows consult _netrc.
+var NetrcPattern = regexp.MustCompile(`^(\.netrc|_netrc|\.authinfo(\.gpg)?)$`)
+
+// SSHHostKeyPattern matches sshd host private and public key filenames.
+var SSHHostKeyPattern = regexp.MustCompile(`^ssh_host_.+_key(\.pub)?$`)
+
 // SSHKeyPattern matches SSH key filenames.
 var SSHKeyPattern = regexp.MustCompile("^id_.+$")
 
-// SSHPublicKeyPattern matches SSH public key filenames.
-var SSHPublicKeyPattern = regexp.MustCompile(`^id_.+\.pub$`)
+// SSHPublicKeyPattern matches SSH public key filenames, regardless of prefix.
+var SSHPublicKeyPattern = regexp.MustCompile(`^.+\.pub$`)
+
+// Check analyzes a path for permission discrepancies,
+// signaling any warnings through the given scanner.
+type Check func(scanner *Scanner, pth string, info os.FileInfo)
+
+// DefaultChecks enumerates the built-in checks, without their routes.
+func DefaultChecks() []Check {
+	var checks []Check
+
+	for _, route := range DefaultRoutes() {
+		checks = append(checks, route.Check)
+	}
+
+	return checks
+}
 
 // Scanner collects warnings.
 type Scanner struct {
@@ -27,44 +68,252 @@ type Scanner struct {
 	DebugCh chan string
 
 	// WarnCh signals permission discrepancies.
-	WarnCh chan string
+	WarnCh chan Warning
 
 	// ErrCh signals errors experienced during scan attempts.
 	ErrCh chan error
 
+	// PassCh signals sensitive paths satisfying their chmod policies.
+	//
+	// Passes are only signaled in Verbose mode.
+	PassCh chan Pass
+
 	// DoneChn signals the end of a bulk scan.
 	DoneCh chan struct{}
 
-	// Home denotes the current user's home directory.
+	// Home denotes the home directory under analysis,
+	// by default the current user's home directory.
 	Home string
-}
 
-// NewScanner constructs a scanner.
-func NewScanner(debug bool) (*Scanner, error) {
-	home, err := os.UserHomeDir()
+	// Homes denotes further home directories under analysis, such as those of every user.
+	//
+	// Home directory policies apply to each path according to its enclosing home directory,
+	// among Home and Homes.
+	Homes []string
 
-	if err != nil {
-		return nil, err
+	// Ignore skips paths matching any of these filepath.Match patterns.
+	//
+	// Patterns are anchored to the entire path.
+	// Relative patterns are resolved against the current working directory,
+	// and walked paths are resolved likewise, such that the pattern ".ssh"
+	// matches the same directory whether the scan root is "." or absolute.
+	// Wildcards do not cross path separators.
+	//
+	// Matching directories are pruned, along with their contents.
+	//
+	// Walks additionally honor patterns listed in IgnoreFilename files,
+	// relative to the directories containing them.
+	Ignore []string
+
+	// KeyPatterns matches SSH private key filenames,
+	// replacing SSHKeyPattern when nonempty.
+	KeyPatterns []*regexp.Regexp
+
+	// Concurrency limits the number of paths statted and inspected at once during a scan.
+	// Zero indicates runtime.NumCPU.
+	//
+	// I/O bound scans, as of network file systems, benefit from values well beyond the number of CPUs.
+	Concurrency int
+
+	// MaxDepth limits traversal to the given number of levels below each scan root.
+	// Zero indicates no limit.
+	//
+	// Depth counts from each scan root, rather than from the file system root.
+	// Paths at the maximum depth are still checked, including directories,
+	// but the contents of directories at the maximum depth are skipped.
+	// For example, a MaxDepth of 2 from /home checks /home/alice/.ssh,
+	// but not /home/alice/.ssh/id_rsa.
+	MaxDepth int
+
+	// Summary enables a closing line of warning counts by file and kind.
+	// The summary is omitted when no warnings occur.
+	Summary bool
+
+	// Quiet suppresses individual warning lines,
+	// implying Summary.
+	Quiet bool
+
+	// Allow accepts further permission bits per kind,
+	// beyond those expected by the built-in policies,
+	// such as 0750 for KindSSHDir where an admin group shares access.
+	Allow map[Kind][]os.FileMode
+
+	// Dedupe reports only the most severe warning per path, per DedupeWarnings,
+	// such that one underlying problem yields one line.
+	Dedupe bool
+
+	// KubeConfigs lists further kubeconfig files, such as those of KUBECONFIG,
+	// held to the policy of ~/.kube/config when walked.
+	KubeConfigs []string
+
+	// Baseline suppresses previously accepted warnings, as loaded by LoadBaseline,
+	// such that only new discrepancies are signaled.
+	// Warnings match by kind, severity, rendered path, message, and modes.
+	Baseline map[Warning]bool
+
+	// Verbose enables pass events for sensitive paths
+	// found to satisfy their chmod policies,
+	// as evidence of inspection.
+	Verbose bool
+
+	// MinSeverity denotes the least severe warning signaled.
+	// Less severe warnings are discarded.
+	MinSeverity Severity
+
+	// FailSeverity denotes the least severe warning
+	// for which reports return a nonzero exit code.
+	// Less severe warnings are still rendered.
+	FailSeverity Severity
+
+	// BasePath, when set, renders warning and pass paths relative to this directory,
+	// such as to keep home directory names out of CI logs.
+	BasePath string
+
+	// Color controls ANSI coloring of warnings and passes in reports.
+	Color ColorMode
+
+	// FollowSymlinks analyzes symlinks according to the permissions of their targets,
+	// warning when a target resides outside of the scan root.
+	// Symlinked directories are descended, skipping targets already walked.
+	// Symlink loops are reported rather than failing the scan.
+	//
+	// Otherwise, symlinked SSH material is reported as such.
+	FollowSymlinks bool
+
+	// WarnMissing enables informational warnings
+	// for .ssh directories lacking expected files.
+	WarnMissing bool
+
+	// CheckPassphrase enables warnings for SSH private keys stored without a passphrase.
+	CheckPassphrase bool
+
+	// CheckWorldWritable enables warnings for group-writable and world-writable paths.
+	CheckWorldWritable bool
+
+	// routes enumerates the analyses applied to walked paths.
+	routes []Route
+
+	// general indexes routes applying to every path.
+	general []int
+
+	// byName indexes routes by path basename.
+	byName map[string][]int
+
+	// byWithin indexes routes by enclosing directory basename.
+	byWithin map[string][]int
+
+	// warned tracks previously signaled warnings,
+	// in order to deduplicate warnings across overlapping scan roots.
+	warned *sync.Map
+
+	// mu guards state accumulated across walks.
+	mu *sync.Mutex
+
+	// sshKeys tracks SSH key filenames by parent directory,
+	// for pairing private and public keys once walks complete.
+	sshKeys map[string]map[string]bool
+
+	// ignoreFiles tracks the patterns of ignore files by absolute directory path.
+	ignoreFiles map[string][]ignorePattern
+
+	// roots tracks scan roots, and paths given to ScanFiles and ScanReader,
+	// bounding the paths modified by Fix.
+	roots []string
+
+	// fsys denotes the file system under ScanFS analysis,
+	// or nil for the host file system.
+	fsys fs.FS
+
+	// visited tracks walked directories when following symlinks,
+	// in order to avoid cycles.
+	visited map[fileID]bool
+
+	// viaSymlink denotes analysis of paths reached through symlinks,
+	// whose warnings chmod must not remediate, lest fixes modify symlink targets.
+	viaSymlink bool
+}
+
+// NewScannerWithHome constructs a scanner
+// for the given home directory.
+func NewScannerWithHome(debug bool, home string) *Scanner {
+	if absHome, err := filepath.Abs(home); err == nil {
+		home = absHome
 	}
 
 	debugCh := make(chan string)
-	warnCh := make(chan string)
+	warnCh := make(chan Warning)
 	errCh := make(chan error)
+	passCh := make(chan Pass)
 	doneCh := make(chan struct{})
 	scanner := Scanner{
-		Debug:   debug,
-		DebugCh: debugCh,
-		WarnCh:  warnCh,
-		ErrCh:   errCh,
-		DoneCh:  doneCh,
-		Home:    home,
+		Debug:       debug,
+		DebugCh:     debugCh,
+		WarnCh:      warnCh,
+		ErrCh:       errCh,
+		PassCh:      passCh,
+		DoneCh:      doneCh,
+		Home:        home,
+		byName:      make(map[string][]int),
+		byWithin:    make(map[string][]int),
+		warned:      new(sync.Map),
+		mu:          new(sync.Mutex),
+		sshKeys:     make(map[string]map[string]bool),
+		visited:     make(map[fileID]bool),
+		ignoreFiles: make(map[string][]ignorePattern),
+	}
+
+	for _, route := range DefaultRoutes() {
+		scanner.RegisterRoute(route)
+	}
+
+	return &scanner
+}
+
+// NewScanner constructs a scanner
+// for the current user's home directory.
+func NewScanner(debug bool) (*Scanner, error) {
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return nil, err
 	}
-	return &scanner, nil
+
+	return NewScannerWithHome(debug, home), nil
+}
+
+// RegisterCheck appends a custom check,
+// applied to each walked path after the built-in checks.
+//
+// Register checks before scanning.
+func (o *Scanner) RegisterCheck(check Check) {
+	o.RegisterRoute(Route{Check: check})
+}
+
+// Register appends a custom check reporting warning messages,
+// applied to each walked path after the built-in checks.
+//
+// Messages are signaled as warnings of the given kind,
+// subject to the same severity filtering, deduplication, and reporting as the built-in checks.
+//
+// Register checks before scanning.
+func (o *Scanner) Register(kind Kind, check func(pth string, info os.FileInfo) []string) {
+	o.RegisterCheck(func(scanner *Scanner, pth string, info os.FileInfo) {
+		for _, msg := range check(pth, info) {
+			scanner.Warn(kind, pth, msg)
+		}
+	})
 }
 
 // CheckFileExists checks paths for existence.
-func (o Scanner) CheckFileExists(pth string, _ os.FileInfo) error {
-	_, err := os.Stat(pth)
+//
+// Paths described by non-symlink file info are known to exist,
+// sparing a stat, whereas symlinks may dangle.
+func (o Scanner) CheckFileExists(pth string, info os.FileInfo) error {
+	if info != nil && info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	_, err := o.stat(pth)
 
 	if errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("%s: not found", pth)
@@ -73,180 +322,1761 @@ func (o Scanner) CheckFileExists(pth string, _ os.FileInfo) error {
 	return nil
 }
 
+// Relative renders a path relative to BasePath, when set.
+//
+// Paths not expressible relative to BasePath, such as those on other Windows volumes,
+// are rendered as given.
+func (o Scanner) Relative(pth string) string {
+	if o.BasePath == "" {
+		return pth
+	}
+
+	absBase, err := filepath.Abs(o.BasePath)
+
+	if err != nil {
+		return pth
+	}
+
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return pth
+	}
+
+	rel, err := filepath.Rel(absBase, absPath)
+
+	if err != nil {
+		return pth
+	}
+
+	return rel
+}
+
+// Warn signals a permission discrepancy,
+// at the default severity of its kind,
+// unless an identical warning has already been signaled.
+func (o *Scanner) Warn(kind Kind, pth string, msg string) {
+	o.signal(Warning{Kind: kind, Severity: kind.Severity(), Path: pth, Message: msg})
+}
+
+// WarnInfo signals a permission discrepancy that chmod alone cannot remediate,
+// noting the observed chmod bits of the given path,
+// at the default severity of its kind,
+// unless an identical warning has already been signaled.
+func (o *Scanner) WarnInfo(kind Kind, pth string, msg string, info os.FileInfo) {
+	actual := chmodBits(info)
+	o.WarnChmod(kind, pth, msg, actual, actual)
+}
+
+// WarnChmod signals a permission discrepancy remediated by chmod to the expected bits,
+// at the default severity of its kind,
+// unless an identical warning has already been signaled.
+func (o *Scanner) WarnChmod(kind Kind, pth string, msg string, actual os.FileMode, expected os.FileMode) {
+	o.signal(Warning{Kind: kind, Severity: kind.Severity(), Path: pth, Message: msg, Observed: true, Actual: actual, Expected: expected})
+}
+
+// signal sends a warning,
+// unless the warning falls below MinSeverity,
+// or an identical warning has already been signaled.
+func (o *Scanner) signal(warning Warning) {
+	if warning.Severity < o.MinSeverity {
+		return
+	}
+
+	warning.Path = o.Relative(warning.Path)
+
+	if o.viaSymlink {
+		warning.Expected = warning.Actual
+	}
+
+	if o.Baseline[warning] {
+		return
+	}
+
+	if o.warned != nil {
+		if _, loaded := o.warned.LoadOrStore(warning, struct{}{}); loaded {
+			return
+		}
+	}
+
+	o.WarnCh <- warning
+}
+
+// Pass signals a sensitive path satisfying its chmod policy,
+// unless not in Verbose mode, or an identical pass has already been signaled.
+func (o *Scanner) Pass(kind Kind, pth string, mode os.FileMode) {
+	if !o.Verbose {
+		return
+	}
+
+	pass := Pass{Kind: kind, Path: o.Relative(pth), Mode: mode}
+
+	if o.warned != nil {
+		if _, loaded := o.warned.LoadOrStore(pass, struct{}{}); loaded {
+			return
+		}
+	}
+
+	o.PassCh <- pass
+}
+
 // ValidateDirectory enforces the given directory policy.
-func (o *Scanner) ValidateDirectory(pth string, info os.FileInfo) {
+func (o *Scanner) ValidateDirectory(kind Kind, pth string, info os.FileInfo) {
 	if !info.IsDir() {
-		o.WarnCh <- fmt.Sprintf("%s: expected directory, got file", pth)
+		o.WarnInfo(kind, pth, "expected directory, got file", info)
 	}
 }
 
 // ValidateFile enforces the given file policy.
-func (o *Scanner) ValidateFile(pth string, info os.FileInfo) {
+func (o *Scanner) ValidateFile(kind Kind, pth string, info os.FileInfo) {
 	if info.IsDir() {
-		o.WarnCh <- fmt.Sprintf("%s: expected file, got directory", pth)
+		o.WarnInfo(kind, pth, "expected file, got directory", info)
 	}
 }
 
 // ValidateChmod enforces the given chmod policy.
-func (o *Scanner) ValidateChmod(pth string, info os.FileInfo, expectedMode os.FileMode) {
+func (o *Scanner) ValidateChmod(kind Kind, pth string, info os.FileInfo, expectedMode os.FileMode) {
+	o.validateChmod(kind, pth, info, expectedMode, "")
+}
+
+// validateChmod enforces the given chmod policy,
+// prefixing any warning message with the given subject, if any.
+func (o *Scanner) validateChmod(kind Kind, pth string, info os.FileInfo, expectedMode os.FileMode, subject string) {
+	observedMode := info.Mode() % 01000
+
+	if expectedMode != observedMode && !o.allowed(kind, observedMode) {
+		actual := chmodBits(info)
+		o.WarnChmod(kind, pth, withSubject(subject, fmt.Sprintf("expected chmod %04o, got %04o", expectedMode, observedMode)), actual, actual&^os.ModePerm|expectedMode)
+		return
+	}
+
+	o.Pass(kind, pth, observedMode)
+}
+
+// ValidateChmodMax enforces the given chmod ceiling policy.
+//
+// Stricter modes, lacking some of the allowed bits, are accepted.
+func (o *Scanner) ValidateChmodMax(kind Kind, pth string, info os.FileInfo, maxMode os.FileMode) {
+	o.validateChmodMax(kind, pth, info, maxMode, "")
+}
+
+// validateChmodMax enforces the given chmod ceiling policy,
+// prefixing any warning message with the given subject, if any.
+func (o *Scanner) validateChmodMax(kind Kind, pth string, info os.FileInfo, maxMode os.FileMode, subject string) {
 	observedMode := info.Mode() % 01000
 
-	if expectedMode != observedMode {
-		o.WarnCh <- fmt.Sprintf("%s: expected chmod %04o, got %04o", pth, expectedMode, observedMode)
+	if observedMode&^maxMode != 0 && !o.allowed(kind, observedMode) {
+		actual := chmodBits(info)
+		o.WarnChmod(kind, pth, withSubject(subject, fmt.Sprintf("expected chmod %04o or stricter, got %04o", maxMode, observedMode)), actual, actual&^(observedMode&^maxMode))
+		return
+	}
+
+	o.Pass(kind, pth, observedMode)
+}
+
+// allowed reports whether the given permission bits are allowlisted for the given kind.
+func (o Scanner) allowed(kind Kind, mode os.FileMode) bool {
+	for _, allowedMode := range o.Allow[kind] {
+		if mode == allowedMode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withSubject prefixes a warning message with the given subject, if any.
+func withSubject(subject string, msg string) string {
+	if subject == "" {
+		return msg
 	}
+
+	return fmt.Sprintf("%s, %s", subject, msg)
 }
 
 // ValidateChmodMask enforces the given chmod mask policy.
-func (o *Scanner) ValidateChmodMask(pth string, info os.FileInfo, expectedMask os.FileMode) {
+//
+// Being applied to paths generally, rather than to sensitive paths,
+// satisfied masks are not signaled as passes.
+func (o *Scanner) ValidateChmodMask(kind Kind, pth string, info os.FileInfo, expectedMask os.FileMode) {
 	observedMode := info.Mode() % 01000
 
 	if expectedMask&observedMode == 0 {
-		o.WarnCh <- fmt.Sprintf("%s: expected chmod mask to union with %04o, got %04o", pth, expectedMask, observedMode)
+		actual := chmodBits(info)
+		o.WarnChmod(kind, pth, fmt.Sprintf("expected chmod mask to union with %04o, got %04o", expectedMask, observedMode), actual, actual|expectedMask)
 	}
 }
 
 // ScanInvisible analyzes paths for missing u+x (directories) or u+r (files) bits.
 func (o Scanner) ScanInvisible(pth string, info os.FileInfo) {
 	if info.IsDir() {
-		o.ValidateChmodMask(pth, info, 0500)
+		o.ValidateChmodMask(KindInvisible, pth, info, 0500)
 	} else {
-		o.ValidateChmodMask(pth, info, 0400)
+		o.ValidateChmodMask(KindInvisible, pth, info, 0400)
 	}
 }
 
-// ScanEtcSSH analyzes /etc or /etc/ssh.
-func (o Scanner) ScanEtcSSH(pth string, info os.FileInfo) {
-	if pth == "/etc" || pth == "/etc/ssh" {
-		o.ValidateDirectory(pth, info)
-		o.ValidateChmod(pth, info, 0755)
+// ScanWorldWritable analyzes files and directories for group-writable or world-writable bits.
+func (o Scanner) ScanWorldWritable(pth string, info os.FileInfo) {
+	if !o.CheckWorldWritable {
+		return
 	}
-}
 
-// ScanUserSSH analyzes .ssh directories.
-func (o Scanner) ScanUserSSH(pth string, info os.FileInfo) {
-	if info.Name() == ".ssh" {
-		o.ValidateDirectory(pth, info)
-		o.ValidateChmod(pth, info, 0700)
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		return
+	}
+
+	observedMode := info.Mode() % 01000
+	actual := chmodBits(info)
+
+	if observedMode&0002 != 0 {
+		o.WarnChmod(KindWorldWritable, pth, fmt.Sprintf("world-writable, got %04o", observedMode), actual, actual&^0022)
+	} else if observedMode&0020 != 0 {
+		o.WarnChmod(KindWorldWritable, pth, fmt.Sprintf("group-writable, got %04o", observedMode), actual, actual&^0022)
 	}
 }
 
-// ScanSSHConfig analyzes .ssh/config files.
-func (o Scanner) ScanSSHConfig(pth string, info os.FileInfo) {
-	if info.Name() == "config" {
-		parent := path.Base(filepath.Dir(pth))
+// ScanEtcSSH analyzes /etc, /etc/ssh, and the sshd host keys, configuration, and moduli within.
+//
+// /etc/ssh and its contents are expected to be owned by root,
+// and host private keys to belong to the root group,
+// or to the ssh_keys group of some distributions.
+func (o Scanner) ScanEtcSSH(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindEtcSSH)
+
+	if pth != "/etc/ssh" && !strings.HasPrefix(pth, "/etc/ssh/") {
+		return
+	}
+
+	if uid, ok := owner(info); ok && uid != 0 {
+		o.WarnInfo(KindOwnership, pth, fmt.Sprintf("expected owner root, got uid %d", uid), info)
+	}
 
-		if parent == ".ssh" {
-			o.ValidateFile(pth, info)
-			o.ValidateChmod(pth, info, 0400)
+	if parentNamed(pth, "ssh") && SSHHostKeyPattern.MatchString(info.Name()) && !SSHPublicKeyPattern.MatchString(info.Name()) {
+		if gid, ok := group(info); ok && gid != 0 && !groupNamed(gid, "ssh_keys") {
+			o.WarnInfo(KindOwnership, pth, fmt.Sprintf("host private key, expected group root or ssh_keys, got gid %d", gid), info)
 		}
 	}
 }
 
-// ScanSSHKeys analyzes .ssh/id_.+(\.pub)? files.
-func (o Scanner) ScanSSHKeys(pth string, info os.FileInfo) {
-	name := info.Name()
+// ScanUserSSH analyzes .ssh directories,
+// and directories nested directly within them, such as ControlPath socket directories.
+func (o Scanner) ScanUserSSH(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindSSHDir)
+}
 
-	if SSHKeyPattern.MatchString(name) {
-		parent := path.Base(filepath.Dir(pth))
+// ExpectedSSHFiles enumerates files expected within .ssh directories, under WarnMissing.
+var ExpectedSSHFiles = []string{"config", "known_hosts"}
 
-		if parent == ".ssh" {
-			o.ValidateFile(pth, info)
+// ScanSSHMissing analyzes .ssh directories for absent ExpectedSSHFiles,
+// such as a missing known_hosts, which prompts on every connection under strict host key checking.
+func (o Scanner) ScanSSHMissing(pth string, info os.FileInfo) {
+	if !o.WarnMissing || info.Name() != ".ssh" || !info.IsDir() {
+		return
+	}
 
-			if SSHPublicKeyPattern.MatchString(name) {
-				o.ValidateChmod(pth, info, 0644)
-			} else {
-				o.ValidateChmod(pth, info, 0600)
-			}
+	for _, name := range ExpectedSSHFiles {
+		expected := filepath.Join(pth, name)
+
+		if _, err := o.lstat(expected); errors.Is(err, os.ErrNotExist) {
+			o.Warn(KindSSHMissing, expected, "missing, expected file")
 		}
 	}
 }
 
-// ScanSSHAuthorizedKeys analyzes authorized_keys files.
-func (o Scanner) ScanSSHAuthorizedKeys(pth string, info os.FileInfo) {
-	if info.Name() == "authorized_keys" {
-		o.ValidateFile(pth, info)
-		o.ValidateChmod(pth, info, 0600)
+// ScanHomeAncestors analyzes the home directories and each of their parent directories,
+// up to the root, for group-writable or world-writable bits,
+// as these undermine every key within the home directories.
+//
+// Being independent of any scan root, the analysis is performed once per scan,
+// rather than per walked path.
+// Ancestors shared among Homes are analyzed once.
+func (o Scanner) ScanHomeAncestors() {
+	if o.evaluatesSecurityDescriptors() {
+		return
+	}
+
+	analyzed := make(map[string]bool)
+
+	for _, home := range o.homes() {
+		o.scanHomeAncestors(home, analyzed)
 	}
 }
 
-// ScanSSHKnownHosts analyzes known_hosts files.
-func (o Scanner) ScanSSHKnownHosts(pth string, info os.FileInfo) {
-	if info.Name() == "known_hosts" {
-		o.ValidateFile(pth, info)
-		o.ValidateChmod(pth, info, 0644)
+// scanHomeAncestors analyzes a home directory and its parent directories,
+// up to the first directory already analyzed.
+func (o Scanner) scanHomeAncestors(home string, analyzed map[string]bool) {
+	for dir := home; !analyzed[dir]; dir = filepath.Dir(dir) {
+		analyzed[dir] = true
+		info, err := o.stat(dir)
+
+		if err != nil {
+			o.ErrCh <- err
+			return
+		}
+
+		observedMode := info.Mode() % 01000
+		actual := chmodBits(info)
+		subject := fmt.Sprintf("ancestor of home directory %s", o.Relative(home))
+
+		if dir == home {
+			subject = "home directory"
+		}
+
+		if observedMode&0020 != 0 {
+			o.WarnChmod(KindHomeAncestor, dir, fmt.Sprintf("group-writable %s, expected chmod g-w, got %04o", subject, observedMode), actual, actual&^0020)
+		}
+
+		if observedMode&0002 != 0 {
+			o.WarnChmod(KindHomeAncestor, dir, fmt.Sprintf("world-writable %s, expected chmod o-w, got %04o", subject, observedMode), actual, actual&^0002)
+		}
+
+		if dir == filepath.Dir(dir) {
+			return
+		}
 	}
 }
 
-// ScanHome analyzes home directories.
-func (o Scanner) ScanHome(pth string, info os.FileInfo) {
-	if info.Name() == o.Home {
-		o.ValidateDirectory(pth, info)
-		o.ValidateChmod(pth, info, 0755)
+// homes enumerates Home and Homes, cleaned, omitting empty entries.
+func (o Scanner) homes() []string {
+	var homes []string
+
+	for _, home := range append([]string{o.Home}, o.Homes...) {
+		if home != "" {
+			homes = append(homes, filepath.Clean(home))
+		}
 	}
+
+	return homes
 }
 
-// Walk traverses a file path recursively,
-// collecting known permission discrepancies.
-func (o *Scanner) Walk(pth string, info os.FileInfo, _ error) error {
-	if o.Debug {
-		o.DebugCh <- fmt.Sprintf("scanning: %s", pth)
+// homeOf resolves the home directory enclosing the given path,
+// the innermost among Home and Homes,
+// or Home when none encloses the path.
+func (o Scanner) homeOf(absPath string) string {
+	enclosing := filepath.Clean(o.Home)
+
+	for _, home := range o.homes() {
+		if within(home, absPath) && (!within(enclosing, absPath) || len(home) > len(enclosing)) {
+			enclosing = home
+		}
+	}
+
+	return enclosing
+}
+
+// ScanAncestors analyzes the parent directories of .ssh directories,
+// up to and including the home directory, for group-writable or world-writable bits,
+// as OpenSSH StrictModes rejects keys beneath such directories.
+//
+// .ssh directories outside of the home directory, such as those of other users,
+// are assumed to reside directly within their own home directories.
+func (o Scanner) ScanAncestors(pth string, info os.FileInfo) {
+	if info.Name() != ".ssh" || !info.IsDir() {
+		return
 	}
 
-	if info == nil {
-		return fmt.Errorf("%s: access denied", pth)
+	sshDir, err := filepath.Abs(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
 	}
 
-	if err := o.CheckFileExists(pth, info); err != nil {
-		return err
+	home := o.homeOf(sshDir)
+
+	if !within(home, sshDir) {
+		home = filepath.Dir(sshDir)
 	}
 
-	if info.Mode()&os.ModeSymlink != 0 {
-		p, err2 := os.Readlink(pth)
+	for ancestor := filepath.Dir(sshDir); ; ancestor = filepath.Dir(ancestor) {
+		ancestorInfo, err2 := o.stat(ancestor)
 
 		if err2 != nil {
-			return err2
+			o.ErrCh <- err2
+			return
+		}
+
+		observedMode := ancestorInfo.Mode() % 01000
+		actual := chmodBits(ancestorInfo)
+
+		if observedMode&0020 != 0 {
+			o.WarnChmod(KindSSHAncestor, ancestor, fmt.Sprintf("group-writable ancestor of %s, expected chmod g-w, got %04o", o.Relative(pth), observedMode), actual, actual&^0020)
+		}
+
+		if observedMode&0002 != 0 {
+			o.WarnChmod(KindSSHAncestor, ancestor, fmt.Sprintf("world-writable ancestor of %s, expected chmod o-w, got %04o", o.Relative(pth), observedMode), actual, actual&^0002)
 		}
 
-		pth = p
+		if ancestor == home || ancestor == filepath.Dir(ancestor) {
+			return
+		}
 	}
+}
 
-	o.ScanInvisible(pth, info)
-	o.ScanHome(pth, info)
-	o.ScanEtcSSH(pth, info)
-	o.ScanUserSSH(pth, info)
-	o.ScanSSHConfig(pth, info)
-	o.ScanSSHKeys(pth, info)
-	o.ScanSSHAuthorizedKeys(pth, info)
-	o.ScanSSHKnownHosts(pth, info)
-	return nil
+// ScanGnuPG analyzes .gnupg directories, their key material, and their configuration files.
+func (o Scanner) ScanGnuPG(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindGnuPG)
 }
 
-// Illuminate pours through the given file paths recursively
-// for known permission discrepancies.
-func Illuminate(roots []string, debug bool) (*Scanner, error) {
-	scanner, err := NewScanner(debug)
+// ScanAWS analyzes .aws directories and their credentials/config files.
+func (o Scanner) ScanAWS(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindAWS)
+}
+
+// ScanAzure analyzes .azure directories and their token caches.
+func (o Scanner) ScanAzure(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindAzure)
+}
+
+// ScanGCloud analyzes the credential databases and application default credentials
+// of .config/gcloud directories.
+func (o Scanner) ScanGCloud(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindGCloud)
+}
+
+// ScanDocker analyzes .docker directories and their config.json files,
+// along with the containers/auth.json files of Podman, Buildah, and Skopeo,
+// which may hold registry credentials.
+func (o Scanner) ScanDocker(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindDocker)
+}
+
+// ScanKube analyzes .kube directories, their config files, and the files listed in KubeConfigs,
+// which may hold cluster bearer tokens and client certificates.
+func (o Scanner) ScanKube(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindKube)
+}
+
+// isKubeConfig reports whether the given path denotes any of KubeConfigs.
+func (o Scanner) isKubeConfig(pth string) bool {
+	if len(o.KubeConfigs) == 0 {
+		return false
+	}
+
+	absPath, err := filepath.Abs(pth)
 
 	if err != nil {
-		return nil, err
+		return false
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(len(roots))
+	for _, kubeConfig := range o.KubeConfigs {
+		if absKubeConfig, err2 := filepath.Abs(kubeConfig); err2 == nil && absKubeConfig == absPath {
+			return true
+		}
+	}
 
-	for _, root := range roots {
-		go func(r string, w *sync.WaitGroup) {
-			defer w.Done()
+	return false
+}
 
-			if err2 := filepath.Walk(r, scanner.Walk); err2 != nil && err2 != io.EOF {
-				scanner.ErrCh <- err2
-			}
-		}(root, &wg)
+// ScanSSHConfig analyzes .ssh/config files, and .ssh/config.d drop-in files,
+// for group or other write access, which ssh rejects.
+func (o Scanner) ScanSSHConfig(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindSSHConfig)
+}
+
+// inSSHConfigDir reports whether the given path resides directly in a .ssh/config.d directory.
+func inSSHConfigDir(pth string) bool {
+	parent := filepath.Dir(pth)
+	return filepath.Base(parent) == "config.d" && filepath.Base(filepath.Dir(parent)) == ".ssh"
+}
+
+// IsSSHKey reports whether the given filename denotes an SSH private or public key.
+//
+// Filenames matching SSHPublicKeyPattern are public keys,
+// taking precedence over the private key patterns.
+// Otherwise, filenames matching any of KeyPatterns,
+// or SSHKeyPattern when KeyPatterns is empty,
+// are private keys.
+func (o Scanner) IsSSHKey(name string) (key bool, public bool) {
+	if SSHPublicKeyPattern.MatchString(name) {
+		return true, true
 	}
 
-	go func() {
-		wg.Wait()
-		scanner.DoneCh <- struct{}{}
+	patterns := o.KeyPatterns
+
+	if len(patterns) == 0 {
+		patterns = []*regexp.Regexp{SSHKeyPattern}
+	}
+
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true, false
+		}
+	}
+
+	return false, false
+}
+
+// ScanSSHKeys analyzes .ssh private and public key files.
+func (o Scanner) ScanSSHKeys(pth string, info os.FileInfo) {
+	if !o.inSSHDir(pth) {
+		return
+	}
+
+	key, public := o.IsSSHKey(info.Name())
+
+	if !key {
+		return
+	}
+
+	o.recordSSHKey(pth, public)
+	o.enforcePolicies(pth, info, KindSSHKey)
+}
+
+// PrivateKeyHeaderPattern matches PEM and OpenSSH private key headers.
+var PrivateKeyHeaderPattern = regexp.MustCompile(`^-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY-----`)
+
+// privateKeySniffLength limits how much of a file is read when sniffing for private key headers.
+const privateKeySniffLength = 64
+
+// ScanSSHKeyContents analyzes .ssh files with unconventional names
+// for private key headers, enforcing private key policy upon them.
+func (o Scanner) ScanSSHKeyContents(pth string, info os.FileInfo) {
+	if !info.Mode().IsRegular() || !o.inSSHDir(pth) {
+		return
+	}
+
+	if key, _ := o.IsSSHKey(info.Name()); key {
+		return
+	}
+
+	f, err := o.open(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	defer func() {
+		_ = f.Close()
 	}()
 
-	return scanner, nil
+	header := make([]byte, privateKeySniffLength)
+	n, err := io.ReadFull(f, header)
+
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		o.ErrCh <- err
+		return
+	}
+
+	if PrivateKeyHeaderPattern.Match(header[:n]) {
+		o.ValidatePrivateKey(KindSSHKey, pth, info)
+	}
+}
+
+// recordSSHKey notes an SSH key for pairing analysis.
+func (o Scanner) recordSSHKey(pth string, public bool) {
+	if o.mu == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	dir := filepath.Dir(pth)
+	keys, ok := o.sshKeys[dir]
+
+	if !ok {
+		keys = make(map[string]bool)
+		o.sshKeys[dir] = keys
+	}
+
+	keys[filepath.Base(pth)] = public
+}
+
+// ScanSSHKeyPairs analyzes the SSH keys encountered during completed walks
+// for private keys lacking public keys, and public keys lacking private keys.
+//
+// OpenSSH certificates, such as id_ed25519-cert.pub, pair with the private keys they certify.
+func (o *Scanner) ScanSSHKeyPairs() {
+	if o.mu == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var dirs []string
+
+	for dir := range o.sshKeys {
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		keys := o.sshKeys[dir]
+		var names []string
+
+		for name := range keys {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			pth := filepath.Join(dir, name)
+
+			if keys[name] {
+				privateName := strings.TrimSuffix(name, ".pub")
+				noun := "public key"
+
+				if certified := strings.TrimSuffix(privateName, "-cert"); certified != privateName {
+					privateName, noun = certified, "certificate"
+				}
+
+				if !o.counterpartExists(keys, dir, privateName) {
+					o.Warn(KindSSHKeyPair, pth, fmt.Sprintf("%s present, private key %s missing", noun, privateName))
+				}
+			} else if !o.counterpartExists(keys, dir, name+".pub") {
+				o.Warn(KindSSHKeyPair, pth, fmt.Sprintf("private key present, public key %s.pub missing, regenerate with ssh-keygen -y", name))
+			}
+		}
+	}
+}
+
+// counterpartExists reports whether the given key counterpart was walked,
+// or otherwise exists outside of the walked paths.
+func (o Scanner) counterpartExists(keys map[string]bool, dir string, name string) bool {
+	if _, ok := keys[name]; ok {
+		return true
+	}
+
+	_, err := o.lstat(filepath.Join(dir, name))
+	return err == nil
+}
+
+// groupOtherAccess describes the access granted to group or other by the given permission bits,
+// such as "readable/writable", or "" when none is granted.
+func groupOtherAccess(mode os.FileMode) string {
+	var access []string
+
+	if mode&0044 != 0 {
+		access = append(access, "readable")
+	}
+
+	if mode&0022 != 0 {
+		access = append(access, "writable")
+	}
+
+	if mode&0011 != 0 {
+		access = append(access, "executable")
+	}
+
+	return strings.Join(access, "/")
+}
+
+// ValidatePrivateKey enforces private key policy.
+//
+// Private keys granting group or other any access are reported as such,
+// in preference to a generic chmod discrepancy.
+// Those readable by group or other are reported as exposed, at SeverityError.
+func (o *Scanner) ValidatePrivateKey(kind Kind, pth string, info os.FileInfo) {
+	observedMode := info.Mode() % 01000
+
+	if observedMode&0077 == 0 || o.allowed(kind, observedMode) {
+		o.ValidateChmodMax(kind, pth, info, 0600)
+		return
+	}
+
+	actual := chmodBits(info)
+	warning := Warning{
+		Kind:     kind,
+		Severity: kind.Severity(),
+		Path:     pth,
+		Message:  fmt.Sprintf("%s by group/other, expected chmod 0600, got %04o", groupOtherAccess(observedMode), observedMode),
+		Observed: true,
+		Actual:   actual,
+		Expected: actual &^ 0177,
+	}
+
+	if observedMode&0044 != 0 {
+		warning.Severity = SeverityError
+		warning.Message = fmt.Sprintf("%s by group/other, exposed private key, expected chmod 0600, got %04o", groupOtherAccess(observedMode), observedMode)
+	}
+
+	o.signal(warning)
+}
+
+// ScanSSHAuthorizedKeys analyzes authorized_keys and legacy authorized_keys2 files.
+func (o Scanner) ScanSSHAuthorizedKeys(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindSSHAuthorizedKeys)
+}
+
+// ScanSSHKnownHosts analyzes known_hosts and legacy known_hosts2 files.
+func (o Scanner) ScanSSHKnownHosts(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindSSHKnownHosts)
+}
+
+// ScanSSHEnvironment analyzes .ssh/environment files,
+// which sshd reads into login sessions when PermitUserEnvironment is enabled.
+func (o Scanner) ScanSSHEnvironment(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindSSHEnvironment)
+}
+
+// inSSHDir reports whether the given path resides in a .ssh directory tree.
+//
+// Paths residing directly in a .ssh directory qualify anywhere.
+// Paths nested more deeply qualify only within the home directory,
+// such as ~/.ssh/work/id_ed25519.
+func (o Scanner) inSSHDir(pth string) bool {
+	parent := filepath.Dir(pth)
+
+	if filepath.Base(parent) == ".ssh" {
+		return true
+	}
+
+	absParent, err := filepath.Abs(parent)
+
+	if err != nil || !strings.Contains(absParent, ".ssh") {
+		return false
+	}
+
+	home := o.homeOf(absParent)
+
+	if !within(home, absParent) {
+		return false
+	}
+
+	for dir := absParent; dir != home && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == ".ssh" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ScanSpecialBits analyzes .ssh directories, their contents, and regular dotfiles
+// for setuid, setgid, or sticky bits.
+//
+// The sticky bit is accepted on directories.
+func (o Scanner) ScanSpecialBits(pth string, info os.FileInfo) {
+	name := info.Name()
+	mode := info.Mode()
+
+	if name != ".ssh" && !o.inSSHDir(pth) && !(mode.IsRegular() && strings.HasPrefix(name, ".")) {
+		return
+	}
+
+	actual := chmodBits(info)
+
+	if mode&os.ModeSetuid != 0 {
+		o.WarnChmod(KindSpecialBits, pth, "unexpected setuid bit, expected chmod u-s", actual, actual&^os.ModeSetuid)
+	}
+
+	if mode&os.ModeSetgid != 0 {
+		o.WarnChmod(KindSpecialBits, pth, "unexpected setgid bit, expected chmod g-s", actual, actual&^os.ModeSetgid)
+	}
+
+	if mode&os.ModeSticky != 0 && !info.IsDir() {
+		o.WarnChmod(KindSpecialBits, pth, "unexpected sticky bit, expected chmod -t", actual, actual&^os.ModeSticky)
+	}
+}
+
+// ScanACL analyzes .ssh directories and their contents for access control list entries
+// granting read or write access to other users or groups, beyond that described by the chmod bits.
+//
+// ACLs are evaluated on Linux and FreeBSD (POSIX.1e, after any mask entry) and macOS (allow entries) only.
+func (o Scanner) ScanACL(pth string, info os.FileInfo) {
+	if o.fsys != nil || (info.Name() != ".ssh" && !o.inSSHDir(pth)) {
+		return
+	}
+
+	grants, err := extendedACL(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	if len(grants) != 0 {
+		o.WarnInfo(KindACL, pth, fmt.Sprintf("access control list grants %s, beyond chmod bits, inspect with %s", strings.Join(grants, ", "), aclInspector), info)
+	}
+}
+
+// ScanSymlink analyzes symlinks standing in for .ssh directories or their contents.
+func (o Scanner) ScanSymlink(pth string, info os.FileInfo) {
+	if info.Name() == ".ssh" || o.inSSHDir(pth) {
+		o.WarnInfo(KindSymlink, pth, "unexpected symlink, expected regular file or directory", info)
+	}
+}
+
+// ScanSymlinkKey analyzes symlinked SSH private keys for targets outside of their .ssh directories,
+// where the permissions of the target directories go unchecked.
+//
+// Unresolvable targets, such as dangling links and loops, are left to the other symlink checks.
+func (o Scanner) ScanSymlinkKey(pth string, info os.FileInfo) {
+	if key, public := o.IsSSHKey(info.Name()); !key || public || !o.inSSHDir(pth) {
+		return
+	}
+
+	target, err := filepath.EvalSymlinks(pth)
+
+	if err != nil {
+		return
+	}
+
+	sshDir, err := enclosingSSHDir(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	realSSHDir, err := filepath.EvalSymlinks(sshDir)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	if !within(realSSHDir, target) {
+		o.Warn(KindSymlink, pth, fmt.Sprintf("private key symlink target %s escapes .ssh directory %s", o.Relative(target), o.Relative(sshDir)))
+	}
+}
+
+// ScanSymlinkEscape analyzes symlinks for targets outside of the given scan root.
+func (o Scanner) ScanSymlinkEscape(root string, pth string) {
+	target, err := filepath.EvalSymlinks(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	if !within(realRoot, target) {
+		o.Warn(KindSymlink, pth, fmt.Sprintf("symlink target %s escapes scan root %s", o.Relative(target), o.Relative(root)))
+	}
+}
+
+// within reports whether the given path resides in the given directory tree.
+func within(dir string, pth string) bool {
+	absDir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return false
+	}
+
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return false
+	}
+
+	if !strings.HasPrefix(absPath, absDir) {
+		return false
+	}
+
+	// Compare whole path components, without allocating a relative path.
+	return len(absPath) == len(absDir) ||
+		strings.HasSuffix(absDir, string(filepath.Separator)) ||
+		absPath[len(absDir)] == filepath.Separator
+}
+
+// ScanOwnership analyzes .ssh directories and their contents
+// for ownership by the current user, or by the owner of the enclosing home directory,
+// as sshd rejects keys owned by other users.
+//
+// Ownership by root is accepted, as by sshd.
+// .ssh directories are assumed to reside directly within their home directories.
+//
+// Ownership is only available on UNIX platforms.
+func (o Scanner) ScanOwnership(pth string, info os.FileInfo) {
+	if info.Name() != ".ssh" && !o.inSSHDir(pth) {
+		return
+	}
+
+	observedUID, ok := owner(info)
+
+	if !ok || observedUID == 0 || observedUID == os.Getuid() {
+		return
+	}
+
+	sshDir, err := enclosingSSHDir(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	homeInfo, err := o.stat(filepath.Dir(sshDir))
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	expectedUID, ok := owner(homeInfo)
+
+	if ok && observedUID != expectedUID {
+		o.WarnInfo(KindOwnership, pth, fmt.Sprintf("expected owner uid %d, got %d", expectedUID, observedUID), info)
+	}
+}
+
+// enclosingSSHDir resolves the nearest .ssh directory among the given path and its ancestors.
+func enclosingSSHDir(pth string) (string, error) {
+	sshDir, err := filepath.Abs(pth)
+
+	if err != nil {
+		return "", err
+	}
+
+	for filepath.Base(sshDir) != ".ssh" && sshDir != filepath.Dir(sshDir) {
+		sshDir = filepath.Dir(sshDir)
+	}
+
+	return sshDir, nil
+}
+
+// inHome reports whether the given path resides directly in a home directory.
+func (o Scanner) inHome(pth string) bool {
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return false
+	}
+
+	return o.isHome(filepath.Dir(absPath))
+}
+
+// ScanNetrc analyzes ~/.netrc, ~/_netrc, ~/.authinfo, and ~/.authinfo.gpg files.
+//
+// Only files residing directly in the home directory are considered,
+// as curl, ftp, and mail clients do not consult project-level copies.
+func (o Scanner) ScanNetrc(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindNetrc)
+}
+
+// ScanGitCredentials analyzes ~/.git-credentials and ~/.config/git/credentials files,
+// where the git store credential helper persists plaintext tokens.
+//
+// git recommends, but does not enforce, chmod 0600.
+func (o Scanner) ScanGitCredentials(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindGitCredentials)
+}
+
+// ScanDatabaseCredentials analyzes ~/.pgpass, ~/.pg_service.conf, ~/.my.cnf, and ~/.mylogin.cnf files.
+//
+// Warnings name the database client concerned, and how it treats the file,
+// as mis-permissioned files surface as confusing authentication failures.
+func (o Scanner) ScanDatabaseCredentials(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindPgpass, KindMySQL)
+}
+
+// ScanPublishTokens analyzes ~/.npmrc, ~/.pypirc, .cargo/credentials.toml, and .gem/credentials files,
+// which may hold tokens for publishing packages.
+func (o Scanner) ScanPublishTokens(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindPublishToken)
+}
+
+// isHome reports whether the given path denotes a home directory, among Home and Homes.
+func (o Scanner) isHome(pth string) bool {
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return false
+	}
+
+	for _, home := range o.homes() {
+		if absPath == home {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ScanHome analyzes home directories.
+func (o Scanner) ScanHome(pth string, info os.FileInfo) {
+	o.enforcePolicies(pth, info, KindHome)
+}
+
+// platformSupportsModes reports whether the given path carries meaningful UNIX permissions.
+//
+// Native Windows synthesizes mode bits from file attributes,
+// as do FAT, exFAT, and NTFS volumes mounted on Linux,
+// whereas WSL reports genuine modes for its UNIX file systems.
+func (o Scanner) platformSupportsModes(pth string) bool {
+	if o.fsys != nil {
+		return true
+	}
+
+	return modesSupported(pth)
+}
+
+// evaluatesSecurityDescriptors reports whether access is analyzed by security descriptor,
+// in place of the chmod based checks, as on native Windows.
+func (o Scanner) evaluatesSecurityDescriptors() bool {
+	return securityDescriptorsSupported && o.fsys == nil
+}
+
+// ScanSecurityDescriptor analyzes .ssh directories and their files on Windows
+// as OpenSSH for Windows does, for ownership by accounts other than
+// the user, SYSTEM, and Administrators, and for access granted to any other accounts.
+// Netrc files in the home directory, such as _netrc, are held to the same standard.
+//
+// The user is the current user, or the owner of the home directory enclosing the path.
+// .ssh directories, public keys, and known_hosts files may be readable by other accounts, though not writable.
+func (o Scanner) ScanSecurityDescriptor(pth string, info os.FileInfo) {
+	name := info.Name()
+	isSSHDir := name == ".ssh" && info.IsDir()
+	isNetrc := info.Mode().IsRegular() && NetrcPattern.MatchString(name) && o.inHome(pth)
+
+	if !isSSHDir && !isNetrc && (!info.Mode().IsRegular() || !o.inSSHDir(pth)) {
+		return
+	}
+
+	home := filepath.Dir(pth)
+
+	if !isNetrc {
+		sshDir, err := enclosingSSHDir(pth)
+
+		if err != nil {
+			o.ErrCh <- err
+			return
+		}
+
+		home = filepath.Dir(sshDir)
+	}
+
+	_, public := o.IsSSHKey(name)
+	readOK := isSSHDir || public || name == "known_hosts" || name == "known_hosts2"
+	discrepancies, err := descriptorDiscrepancies(pth, home, readOK)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	for _, discrepancy := range discrepancies {
+		o.Warn(KindSecurityDescriptor, pth, discrepancy)
+	}
+}
+
+// Ignored reports whether the given path matches any ignore pattern,
+// including those of ignore files loaded from its ancestors during the walk.
+func (o Scanner) Ignored(pth string) (bool, error) {
+	absPath, err := filepath.Abs(pth)
+
+	if err != nil {
+		return false, err
+	}
+
+	if o.ignoredByFiles(absPath) {
+		return true, nil
+	}
+
+	return o.ignoredByOptions(absPath)
+}
+
+// ignoredByOptions reports whether the given absolute path matches any Ignore pattern.
+func (o Scanner) ignoredByOptions(absPath string) (bool, error) {
+	for _, pattern := range o.Ignore {
+		absPattern, err2 := filepath.Abs(pattern)
+
+		if err2 != nil {
+			return false, err2
+		}
+
+		match, err2 := filepath.Match(absPattern, absPath)
+
+		if err2 != nil {
+			return false, fmt.Errorf("%s: %v", pattern, err2)
+		}
+
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Walk produces a filepath.WalkFunc for traversing the given scan root recursively,
+// collecting known permission discrepancies.
+//
+// Unreadable paths are signaled as errors, without interrupting the rest of the walk.
+func (o *Scanner) Walk(root string) filepath.WalkFunc {
+	return func(pth string, info os.FileInfo, walkErr error) error {
+		ignored, err := o.Ignored(pth)
+
+		if err != nil {
+			return err
+		}
+
+		if ignored {
+			if o.Debug {
+				o.DebugCh <- fmt.Sprintf("ignoring: %s", pth)
+			}
+
+			if info == nil || !info.IsDir() {
+				return nil
+			}
+
+			// Descend into the directory, without inspecting it, when later ! patterns may re-include its entries.
+			reincludable, err2 := o.reincludable(pth)
+
+			if err2 != nil {
+				return err2
+			}
+
+			if !reincludable || (o.MaxDepth > 0 && depth(root, pth) >= o.MaxDepth) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if o.Debug {
+			o.DebugCh <- fmt.Sprintf("scanning: %s", pth)
+		}
+
+		if info == nil {
+			if walkErr == nil {
+				walkErr = fmt.Errorf("%s: access denied", pth)
+			}
+
+			o.ErrCh <- walkErr
+			return nil
+		}
+
+		if (pth == root || info.IsDir()) && !o.platformSupportsModes(pth) {
+			if !o.evaluatesSecurityDescriptors() {
+				o.Warn(KindPlatform, pth, "permission checks unreliable on non-UNIX filesystem")
+
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			// Security descriptor evaluation is the norm on Windows, rather than a discrepancy.
+			if pth == root && o.Debug {
+				o.DebugCh <- fmt.Sprintf("chmod checks unavailable on Windows, evaluating security descriptors only: %s", pth)
+			}
+		}
+
+		if info.IsDir() && walkErr == nil {
+			if err = o.loadIgnoreFile(pth); err != nil {
+				o.ErrCh <- err
+			}
+		}
+
+		// Unreadable directories revisit walkFn with their ReadDir errors, having been recorded as visited already.
+		if o.FollowSymlinks && info.IsDir() && walkErr == nil && !o.visit(info) {
+			if o.Debug {
+				o.DebugCh <- fmt.Sprintf("skipping previously walked directory: %s", pth)
+			}
+
+			return filepath.SkipDir
+		}
+
+		if err = o.Inspect(root, pth, info); err != nil {
+			o.ErrCh <- err
+		}
+
+		if o.FollowSymlinks && o.fsys == nil && info.Mode()&os.ModeSymlink != 0 {
+			o.descend(root, pth)
+		}
+
+		if walkErr != nil {
+			o.ErrCh <- walkErr
+
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+
+		if o.MaxDepth > 0 && info.IsDir() && depth(root, pth) >= o.MaxDepth {
+			return filepath.SkipDir
+		}
+
+		return nil
+	}
+}
+
+// visit records a directory as walked,
+// reporting whether the directory was previously unvisited.
+//
+// Directories lacking a device and inode identity are never considered visited.
+func (o *Scanner) visit(info os.FileInfo) bool {
+	id, ok := identify(info)
+
+	if !ok || o.mu == nil {
+		return true
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.visited[id] {
+		return false
+	}
+
+	o.visited[id] = true
+	return true
+}
+
+// descend walks the target of a directory symlink,
+// reporting paths relative to the symlink.
+//
+// Targets already walked are skipped, preventing cycles.
+// Without device and inode identities, as on Windows, symlinked directories are not descended.
+func (o *Scanner) descend(root string, pth string) {
+	targetInfo, err := os.Stat(pth)
+
+	if err != nil || !targetInfo.IsDir() {
+		return
+	}
+
+	if _, ok := identify(targetInfo); !ok || !o.visit(targetInfo) {
+		if o.Debug {
+			o.DebugCh <- fmt.Sprintf("skipping previously walked symlink target: %s", pth)
+		}
+
+		return
+	}
+
+	target, err := filepath.EvalSymlinks(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	linked := *o
+	linked.viaSymlink = true
+	walk := linked.Walk(root)
+
+	if err = filepath.Walk(target, func(p string, i os.FileInfo, walkErr error) error {
+		if p == target {
+			return nil
+		}
+
+		rel, err2 := filepath.Rel(target, p)
+
+		if err2 != nil {
+			return err2
+		}
+
+		return walk(filepath.Join(pth, rel), i, walkErr)
+	}); err != nil {
+		o.ErrCh <- err
+	}
+}
+
+// depth counts the path separators between a scan root and a path within it.
+//
+// The root itself has depth zero, and its immediate children depth one.
+func depth(root string, pth string) int {
+	rel, err := filepath.Rel(root, pth)
+
+	if err != nil || rel == "." {
+		return 0
+	}
+
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// Inspect applies the checks routed to a single path.
+//
+// The root identifies the scan root containing the path, if any.
+func (o *Scanner) Inspect(root string, pth string, info os.FileInfo) error {
+	if err := o.CheckFileExists(pth, info); err != nil {
+		return err
+	}
+
+	// UNIX sockets, such as ssh ControlMaster sockets, as well as named pipes and devices,
+	// carry no file contents to protect.
+	if info.Mode()&(os.ModeSocket|os.ModeNamedPipe|os.ModeDevice) != 0 {
+		if o.Debug {
+			o.DebugCh <- fmt.Sprintf("skipping special file: %s", pth)
+		}
+
+		return nil
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if o.fsys != nil {
+			o.ScanSymlink(pth, info)
+		} else if o.FollowSymlinks {
+			targetInfo, err := os.Stat(pth)
+
+			if isSymlinkLoop(err) {
+				o.WarnInfo(KindSymlink, pth, "symlink loop, target unresolvable", info)
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			o.ScanSymlinkKey(pth, info)
+
+			if root != "" {
+				o.ScanSymlinkEscape(root, pth)
+			}
+
+			info = targetInfo
+		} else {
+			o.ScanSymlink(pth, info)
+			o.ScanSymlinkKey(pth, info)
+
+			p, err := os.Readlink(pth)
+
+			if err != nil {
+				return err
+			}
+
+			pth = p
+		}
+
+		linked := *o
+		linked.viaSymlink = true
+		o = &linked
+	}
+
+	if o.evaluatesSecurityDescriptors() {
+		o.ScanSecurityDescriptor(pth, info)
+		return nil
+	}
+
+	o.dispatch(pth, info)
+
+	return nil
+}
+
+// ScanContext pours through the given file paths recursively
+// for known permission discrepancies,
+// in the background.
+//
+// Each root is walked independently,
+// so that errors in one root do not abort the others.
+// Paths are statted and inspected concurrently, up to Concurrency paths at once,
+// such that events arrive in no particular order.
+// Reports nonetheless render warnings and passes in stable order.
+//
+// Cancelling the context stops the walks promptly,
+// signaling an error wrapping the context error for each unfinished root.
+// Warnings signaled prior to cancellation remain valid, partial results.
+func (o *Scanner) ScanContext(ctx context.Context, roots []string) {
+	concurrency := o.Concurrency
+
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	for _, root := range roots {
+		o.addRoot(root)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	wg.Add(len(roots) + 1)
+
+	go func() {
+		defer wg.Done()
+		o.ScanHomeAncestors()
+	}()
+
+	for _, root := range roots {
+		go func(r string, w *sync.WaitGroup) {
+			defer w.Done()
+
+			walk := o.Walk(r)
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := walkParallel(r, sem, func(pth string, info os.FileInfo, err error) error {
+				if err2 := ctx.Err(); err2 != nil {
+					return fmt.Errorf("%s: scan aborted: %w", r, err2)
+				}
+
+				return walk(pth, info, err)
+			}); err != nil && err != io.EOF {
+				o.ErrCh <- err
+			}
+		}(root, &wg)
+	}
+
+	go func() {
+		wg.Wait()
+
+		if ctx.Err() == nil {
+			o.ScanSSHKeyPairs()
+		}
+
+		o.DoneCh <- struct{}{}
+	}()
+}
+
+// Scan pours through the given file paths recursively
+// for known permission discrepancies,
+// in the background.
+func (o *Scanner) Scan(roots []string) {
+	o.ScanContext(context.Background(), roots)
+}
+
+// ScanFiles analyzes the given file paths,
+// without descending into directories,
+// in the background.
+//
+// Missing paths are skipped.
+func (o *Scanner) ScanFiles(paths []string) {
+	o.ScanFilesContext(context.Background(), paths)
+}
+
+// ScanFilesContext analyzes the given file paths,
+// without descending into directories,
+// in the background.
+//
+// Missing paths are skipped.
+//
+// Cancelling the context skips the remaining paths,
+// signaling an error wrapping the context error.
+func (o *Scanner) ScanFilesContext(ctx context.Context, paths []string) {
+	go func() {
+		for _, pth := range paths {
+			if err := ctx.Err(); err != nil {
+				o.ErrCh <- fmt.Errorf("scan aborted: %w", err)
+				break
+			}
+
+			o.inspectFile(pth)
+		}
+
+		o.DoneCh <- struct{}{}
+	}()
+}
+
+// ScanReader analyzes newline-separated file paths read from r,
+// such as the output of find,
+// without descending into directories,
+// in the background.
+//
+// Blank lines and missing paths are skipped.
+func (o *Scanner) ScanReader(r io.Reader) {
+	o.ScanReaderContext(context.Background(), r)
+}
+
+// ScanReaderContext analyzes newline-separated file paths read from r,
+// such as the output of find,
+// without descending into directories,
+// in the background.
+//
+// Blank lines and missing paths are skipped.
+//
+// Cancelling the context skips the remaining paths,
+// signaling an error wrapping the context error.
+// A read blocked on r continues until it returns.
+func (o *Scanner) ScanReaderContext(ctx context.Context, r io.Reader) {
+	go func() {
+		lines := bufio.NewScanner(r)
+
+		for lines.Scan() {
+			if err := ctx.Err(); err != nil {
+				o.ErrCh <- fmt.Errorf("scan aborted: %w", err)
+				break
+			}
+
+			if pth := strings.TrimSuffix(lines.Text(), "\r"); pth != "" {
+				o.inspectFile(pth)
+			}
+		}
+
+		if err := lines.Err(); err != nil {
+			o.ErrCh <- err
+		}
+
+		o.DoneCh <- struct{}{}
+	}()
+}
+
+// addRoot records a scan root.
+func (o *Scanner) addRoot(root string) {
+	if o.mu != nil {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+	}
+
+	o.roots = append(o.roots, root)
+}
+
+// inspectFile analyzes a single path, without descending into directories.
+//
+// Missing paths are skipped.
+func (o *Scanner) inspectFile(pth string) {
+	o.addRoot(pth)
+	ignored, err := o.Ignored(pth)
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	if ignored {
+		return
+	}
+
+	info, err := os.Lstat(pth)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	if err != nil {
+		o.ErrCh <- err
+		return
+	}
+
+	if !o.platformSupportsModes(pth) && !o.evaluatesSecurityDescriptors() {
+		o.Warn(KindPlatform, pth, "permission checks unreliable on non-UNIX filesystem")
+		return
+	}
+
+	if err = o.Inspect("", pth, info); err != nil {
+		o.ErrCh <- err
+	}
+}
+
+// ReportTo renders scan events to the given writer,
+// until the end of the scan.
+//
+// Warnings are rendered at the end of the scan, in stable order,
+// preceded by passes in Verbose mode.
+// Errors, such as unreadable directories, are rendered as they occur,
+// prefixed to distinguish them from warnings.
+//
+// Returns a nonzero exit code when any errors,
+// or any warnings of at least FailSeverity, occurred.
+func (o *Scanner) ReportTo(w io.Writer) int {
+	logger := log.New(w, "", log.LstdFlags)
+	color := o.Color.colorize(w)
+	status := 0
+	var warnings []Warning
+	var passes []Pass
+
+	for {
+		select {
+		case msg := <-o.DebugCh:
+			logger.Println(msg)
+		case warning := <-o.WarnCh:
+			if warning.Severity >= o.FailSeverity {
+				status = 1
+			}
+
+			warnings = append(warnings, warning)
+		case pass := <-o.PassCh:
+			passes = append(passes, pass)
+		case err := <-o.ErrCh:
+			status = 1
+			logger.Println(paint(color, ansiRed, fmt.Sprintf("error: %s", err)))
+		case <-o.DoneCh:
+			SortPasses(passes)
+
+			for _, pass := range passes {
+				logger.Println(paint(color, ansiGreen, fmt.Sprintf("ok: %s", pass)))
+			}
+
+			SortWarnings(warnings)
+
+			if o.Dedupe {
+				warnings = DedupeWarnings(warnings)
+			}
+
+			if !o.Quiet {
+				for _, warning := range warnings {
+					logger.Println(paint(color, ansiRed, fmt.Sprintf("warning: %s", warning)))
+				}
+			}
+
+			if (o.Summary || o.Quiet) && len(warnings) != 0 {
+				logger.Println(Summarize(warnings))
+			}
+
+			return status
+		}
+	}
+}
+
+// Collect gathers scan events until the end of the scan,
+// returning warnings in stable order,
+// along with every error encountered, joined.
+//
+// Debug events and passes are discarded.
+func (o *Scanner) Collect() ([]Warning, error) {
+	warnings, err := o.collect()
+
+	if o.Dedupe {
+		warnings = DedupeWarnings(warnings)
+	}
+
+	return warnings, err
+}
+
+// collect gathers scan events until the end of the scan,
+// returning every warning in stable order,
+// along with every error encountered, joined.
+func (o *Scanner) collect() ([]Warning, error) {
+	var warnings []Warning
+	var errs []error
+
+	for {
+		select {
+		case <-o.DebugCh:
+		case <-o.PassCh:
+		case warning := <-o.WarnCh:
+			warnings = append(warnings, warning)
+		case err := <-o.ErrCh:
+			errs = append(errs, err)
+		case <-o.DoneCh:
+			SortWarnings(warnings)
+			return warnings, errors.Join(errs...)
+		}
+	}
+}
+
+// ScanStream pours through the given file paths recursively
+// for known permission discrepancies,
+// calling fn for each warning as it occurs, without accumulating warnings,
+// and blocking until the end of the scan.
+//
+// Warnings arrive in no particular order, and Dedupe does not apply.
+// Debug events and passes are discarded.
+//
+// When fn returns an error, the scan is cancelled, and that error is returned.
+// Otherwise, returns every error encountered, joined.
+func (o *Scanner) ScanStream(roots []string, fn func(Warning) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.ScanContext(ctx, roots)
+	var fnErr error
+	var errs []error
+
+	for {
+		select {
+		case <-o.DebugCh:
+		case <-o.PassCh:
+		case warning := <-o.WarnCh:
+			if fnErr != nil {
+				continue
+			}
+
+			if fnErr = fn(warning); fnErr != nil {
+				cancel()
+			}
+		case err := <-o.ErrCh:
+			errs = append(errs, err)
+		case <-o.DoneCh:
+			if fnErr != nil {
+				return fnErr
+			}
+
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// Summarize renders warning counts, distinct file counts,
+// and warning counts by kind in descending order of frequency.
+func Summarize(warnings []Warning) string {
+	counts := make(map[Kind]int)
+	paths := make(map[string]struct{})
+
+	for _, warning := range warnings {
+		counts[warning.Kind]++
+		paths[warning.Path] = struct{}{}
+	}
+
+	var kinds []Kind
+
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		if counts[kinds[i]] != counts[kinds[j]] {
+			return counts[kinds[i]] > counts[kinds[j]]
+		}
+
+		return kinds[i] < kinds[j]
+	})
+
+	var tallies []string
+
+	for _, kind := range kinds {
+		tallies = append(tallies, fmt.Sprintf("%d %s", counts[kind], kind))
+	}
+
+	return fmt.Sprintf(
+		"sunshine: %s across %s (%s)",
+		pluralize(len(warnings), "warning"),
+		pluralize(len(paths), "file"),
+		strings.Join(tallies, ", "),
+	)
+}
+
+// pluralize renders a count of the given noun.
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// Report renders scan events to standard error,
+// until the end of the scan.
+//
+// Returns a nonzero exit code when any warnings or errors occurred.
+func (o *Scanner) Report() int {
+	return o.ReportTo(os.Stderr)
+}
+
+// Illuminate pours through the given file paths recursively
+// for known permission discrepancies.
+func Illuminate(roots []string, debug bool) (*Scanner, error) {
+	scanner, err := NewScanner(debug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scanner.Scan(roots)
+	return scanner, nil
+}
+
+// ReportTo pours through the given file paths recursively
+// for known permission discrepancies,
+// rendering scan events to the given writer.
+//
+// Returns a nonzero exit code when any warnings or errors occurred.
+func ReportTo(roots []string, debug bool, w io.Writer) int {
+	scanner, err := Illuminate(roots, debug)
+
+	if err != nil {
+		log.New(w, "", log.LstdFlags).Println(err)
+		return 1
+	}
+
+	return scanner.ReportTo(w)
+}
+
+// Report pours through the given file paths recursively
+// for known permission discrepancies,
+// rendering scan events to standard error.
+//
+// Returns a nonzero exit code when any warnings or errors occurred.
+func Report(roots []string, debug bool) int {
+	return ReportTo(roots, debug, os.Stderr)
 }
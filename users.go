@@ -0,0 +1,116 @@
+package sunshine
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PasswdPath denotes the conventional user account database.
+const PasswdPath = "/etc/passwd"
+
+// nonLoginShells enumerates the shells of accounts barred from interactive login.
+var nonLoginShells = map[string]bool{
+	"nologin":  true,
+	"false":    true,
+	"sync":     true,
+	"shutdown": true,
+	"halt":     true,
+}
+
+// ParsePasswd reads the home directories of accounts with login shells
+// from passwd(5) entries, in order of appearance, without duplicates.
+//
+// Blank lines, comments, and entries lacking a home directory are skipped.
+func ParsePasswd(r io.Reader) ([]string, error) {
+	var homes []string
+	seen := make(map[string]bool)
+	lines := bufio.NewScanner(r)
+
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+
+		if len(fields) < 7 {
+			continue
+		}
+
+		home, shell := fields[5], fields[6]
+
+		if home == "" || home == "/" || nonLoginShells[filepath.Base(shell)] || seen[home] {
+			continue
+		}
+
+		seen[home] = true
+		homes = append(homes, home)
+	}
+
+	return homes, lines.Err()
+}
+
+// existingDirs selects the paths denoting existing directories, in order.
+func existingDirs(paths []string) []string {
+	var dirs []string
+
+	for _, pth := range paths {
+		if info, err := os.Stat(pth); err == nil && info.IsDir() {
+			dirs = append(dirs, pth)
+		}
+	}
+
+	return dirs
+}
+
+// UserHomes enumerates the existing home directories of accounts with login shells, per PasswdPath.
+//
+// Where PasswdPath lists no such directories, as on macOS, where accounts reside in Directory Services,
+// the directories within /home and /Users are enumerated instead.
+func UserHomes() ([]string, error) {
+	var homes []string
+	f, err := os.Open(PasswdPath)
+
+	if err == nil {
+		candidates, err2 := ParsePasswd(f)
+		_ = f.Close()
+
+		if err2 != nil {
+			return nil, err2
+		}
+
+		homes = existingDirs(candidates)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(homes) != 0 {
+		return homes, nil
+	}
+
+	for _, parent := range []string{"/home", "/Users"} {
+		entries, err2 := os.ReadDir(parent)
+
+		if errors.Is(err2, os.ErrNotExist) {
+			continue
+		}
+
+		if err2 != nil {
+			return nil, err2
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				homes = append(homes, filepath.Join(parent, entry.Name()))
+			}
+		}
+	}
+
+	return homes, nil
+}
@@ -0,0 +1,76 @@
+package sunshine
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePasswd(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		passwd   string
+		expected []string
+	}{
+		{
+			name:     "login shells",
+			passwd:   "root:x:0:0:root:/root:/bin/bash\nalice:x:1000:1000:Alice:/home/alice:/bin/zsh\n",
+			expected: []string{"/root", "/home/alice"},
+		},
+		{
+			name:     "non-login shells",
+			passwd:   "daemon:x:1:1::/usr/sbin:/usr/sbin/nologin\nbin:x:2:2::/bin:/bin/false\nsync:x:4:65534::/bin:/bin/sync\nbob:x:1001:1001::/home/bob:/bin/sh\n",
+			expected: []string{"/home/bob"},
+		},
+		{
+			name:     "comments and blank lines",
+			passwd:   "# local accounts\n\n   \nalice:x:1000:1000::/home/alice:/bin/sh\n\n#bob:x:1001:1001::/home/bob:/bin/sh\n",
+			expected: []string{"/home/alice"},
+		},
+		{
+			name:     "duplicate homes",
+			passwd:   "alice:x:1000:1000::/home/alice:/bin/sh\nalice2:x:1002:1000::/home/alice:/bin/bash\n",
+			expected: []string{"/home/alice"},
+		},
+		{
+			name:     "root directory homes",
+			passwd:   "nobody:x:65534:65534::/:/bin/sh\nalice:x:1000:1000::/home/alice:/bin/sh\n",
+			expected: []string{"/home/alice"},
+		},
+		{
+			name:     "missing homes and truncated entries",
+			passwd:   "ghost:x:1003:1003:::/bin/sh\ntruncated:x:1004:1004\nalice:x:1000:1000::/home/alice:/bin/sh\n",
+			expected: []string{"/home/alice"},
+		},
+		{
+			name:   "empty",
+			passwd: "",
+		},
+	} {
+		homes, err := ParsePasswd(strings.NewReader(tc.passwd))
+
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(homes, tc.expected) {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, homes)
+		}
+	}
+}
+
+func TestExistingDirs(t *testing.T) {
+	dir := t.TempDir()
+	alice := filepath.Join(dir, "alice")
+	file := filepath.Join(dir, "file")
+	mkdirFixture(t, alice, 0700)
+	writeFixture(t, file, 0644, "")
+
+	expected := []string{alice}
+
+	if got := existingDirs([]string{filepath.Join(dir, "missing"), alice, file}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,142 @@
+package sunshine
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// parallelWalk fans a file tree walk out across goroutines,
+// bounded by a semaphore shared among walks.
+type parallelWalk struct {
+	// walkFn visits each path, as with filepath.Walk.
+	walkFn filepath.WalkFunc
+
+	// sem bounds the goroutines statting and visiting paths, beyond the calling goroutine.
+	sem chan struct{}
+
+	// wg tracks spawned goroutines.
+	wg sync.WaitGroup
+
+	// once guards err.
+	once sync.Once
+
+	// err records the first error aborting the walk.
+	err error
+
+	// done closes upon abort.
+	done chan struct{}
+}
+
+// walkParallel walks the file tree rooted at root, calling walkFn for each path, as filepath.Walk does,
+// statting and visiting directory entries in separate goroutines while sem has capacity,
+// and otherwise in the calling goroutine,
+// such that slow stat calls, as on network file systems, overlap.
+//
+// Directories are visited before their entries,
+// but paths are otherwise visited in no particular order.
+// SkipDir skips directories, and is ignored for files.
+func walkParallel(root string, sem chan struct{}, walkFn filepath.WalkFunc) error {
+	info, err := os.Lstat(root)
+
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		w := parallelWalk{walkFn: walkFn, sem: sem, done: make(chan struct{})}
+		w.walk(root, info)
+		w.wg.Wait()
+		err = w.err
+	}
+
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// abort records the first error, halting the walk.
+func (o *parallelWalk) abort(err error) {
+	o.once.Do(func() {
+		o.err = err
+		close(o.done)
+	})
+}
+
+// aborted reports whether the walk has halted.
+func (o *parallelWalk) aborted() bool {
+	select {
+	case <-o.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// visit calls walkFn, aborting on errors other than SkipDir.
+//
+// Reports whether to proceed with the path.
+func (o *parallelWalk) visit(pth string, info os.FileInfo, walkErr error) bool {
+	err := o.walkFn(pth, info, walkErr)
+
+	if err == nil {
+		return true
+	}
+
+	if err != filepath.SkipDir {
+		o.abort(err)
+	}
+
+	return false
+}
+
+// walk visits a path, descending into directories.
+func (o *parallelWalk) walk(pth string, info os.FileInfo) {
+	if o.aborted() || !o.visit(pth, info, nil) || !info.IsDir() {
+		return
+	}
+
+	entries, err := os.ReadDir(pth)
+
+	if err != nil {
+		o.visit(pth, info, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if o.aborted() {
+			return
+		}
+
+		select {
+		case o.sem <- struct{}{}:
+			o.wg.Add(1)
+
+			go func(e fs.DirEntry) {
+				defer o.wg.Done()
+				defer func() { <-o.sem }()
+				o.walkEntry(pth, e)
+			}(entry)
+		default:
+			o.walkEntry(pth, entry)
+		}
+	}
+}
+
+// walkEntry describes and walks an entry of the given directory,
+// as fs.WalkDir does, without following symlinks.
+//
+// Where directory reads report file metadata, as on Windows, no further stat occurs.
+func (o *parallelWalk) walkEntry(dir string, entry fs.DirEntry) {
+	pth := filepath.Join(dir, entry.Name())
+	info, err := entry.Info()
+
+	if err != nil {
+		o.visit(pth, nil, err)
+		return
+	}
+
+	o.walk(pth, info)
+}
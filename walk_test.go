@@ -0,0 +1,162 @@
+package sunshine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// walkFixtures creates a tree of dirs directories holding files files each,
+// interspersed with misconfigured SSH material.
+func walkFixtures(t testing.TB, dirs int, files int) string {
+	t.Helper()
+	root := t.TempDir()
+	mkdirFixture(t, filepath.Join(root, ".ssh"), 0755)
+	writeFixture(t, filepath.Join(root, ".ssh", "id_rsa"), 0644, "")
+	writeFixture(t, filepath.Join(root, ".ssh", "id_rsa.pub"), 0666, "")
+	writeFixture(t, filepath.Join(root, ".ssh", "config"), 0666, "")
+
+	for i := 0; i < dirs; i++ {
+		for j := 0; j < files; j++ {
+			writeFixture(t, filepath.Join(root, "src", fmt.Sprintf("d%d", i), fmt.Sprintf("f%d", j)), 0644, "")
+		}
+
+		writeFixture(t, filepath.Join(root, "src", fmt.Sprintf("d%d", i), ".ssh", "id_ed25519"), 0640, "")
+	}
+
+	return root
+}
+
+// walkPaths collects the paths visited by a walk, sorted,
+// skipping directories named skip.
+func walkPaths(t *testing.T, walk func(string, filepath.WalkFunc) error, root string, skip string) []string {
+	t.Helper()
+	var mu sync.Mutex
+	var paths []string
+
+	if err := walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == skip {
+			return filepath.SkipDir
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, pth)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+func TestWalkParallel(t *testing.T) {
+	root := walkFixtures(t, 10, 10)
+	expected := walkPaths(t, filepath.Walk, root, "d3")
+
+	for _, concurrency := range []int{1, 4, 64} {
+		got := walkPaths(t, func(root string, walkFn filepath.WalkFunc) error {
+			return walkParallel(root, make(chan struct{}, concurrency), walkFn)
+		}, root, "d3")
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("concurrency %d: expected paths %v, got %v", concurrency, expected, got)
+		}
+	}
+}
+
+func TestScanConcurrency(t *testing.T) {
+	root := walkFixtures(t, 10, 10)
+	var expected []Warning
+
+	for _, concurrency := range []int{1, 4, 64} {
+		scanner := NewScannerWithHome(false, root)
+		scanner.Concurrency = concurrency
+		warnings := scanWarnings(t, scanner, root)
+
+		sort.Slice(warnings, func(i, j int) bool {
+			return fmt.Sprint(warnings[i]) < fmt.Sprint(warnings[j])
+		})
+
+		if len(warnings) == 0 {
+			t.Fatalf("concurrency %d: expected warnings", concurrency)
+		}
+
+		if expected == nil {
+			expected = warnings
+		} else if !reflect.DeepEqual(warnings, expected) {
+			t.Errorf("concurrency %d: expected warnings %v, got %v", concurrency, expected, warnings)
+		}
+	}
+}
+
+// BenchmarkWalkParallel compares walkParallel against the serial filepath.Walk,
+// over a tree of 1,000 files.
+func BenchmarkWalkParallel(b *testing.B) {
+	root := walkFixtures(b, 50, 20)
+	noop := func(_ string, _ os.FileInfo, err error) error { return err }
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := filepath.Walk(root, noop); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			sem := make(chan struct{}, concurrency)
+
+			for i := 0; i < b.N; i++ {
+				if err := walkParallel(root, sem, noop); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestUnreadableDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root reads unreadable directories")
+	}
+
+	for _, follow := range []bool{false, true} {
+		home := t.TempDir()
+		locked := filepath.Join(home, "locked")
+		writeFixture(t, filepath.Join(locked, "id_rsa"), 0600, "")
+		mkdirFixture(t, locked, 0000)
+
+		t.Cleanup(func() {
+			_ = os.Chmod(locked, 0700)
+		})
+
+		scanner := NewScannerWithHome(false, home)
+		scanner.FollowSymlinks = follow
+		scanner.Scan([]string{home})
+		_, err := scanner.Collect()
+		var errs []error
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+
+		var pathErr *fs.PathError
+
+		if len(errs) != 1 || !errors.As(errs[0], &pathErr) || pathErr.Path != locked || !errors.Is(pathErr, fs.ErrPermission) {
+			t.Errorf("follow %v: expected a lone permission error for %s, got %v", follow, locked, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package sunshine
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Kind classifies warnings by the subsystem concerned.
+type Kind string
+
+const (
+	// KindPlatform denotes file systems lacking UNIX permissions.
+	KindPlatform Kind = "platform"
+
+	// KindInvisible denotes paths missing owner read or traversal bits.
+	KindInvisible Kind = "invisible"
+
+	// KindWorldWritable denotes group-writable or world-writable paths.
+	KindWorldWritable Kind = "world-writable"
+
+	// KindHome denotes home directories.
+	KindHome Kind = "home"
+
+	// KindEtcSSH denotes /etc, /etc/ssh, and the sshd host keys, configuration, and moduli within.
+	KindEtcSSH Kind = "etc-ssh"
+
+	// KindSSHDir denotes .ssh directories, and directories nested directly within them.
+	KindSSHDir Kind = "ssh-dir"
+
+	// KindHomeAncestor denotes home directories and their parent directories, up to the root.
+	KindHomeAncestor Kind = "home-ancestor"
+
+	// KindSSHAncestor denotes parent directories of .ssh directories.
+	KindSSHAncestor Kind = "ssh-ancestor"
+
+	// KindSSHMissing denotes expected .ssh files found absent.
+	KindSSHMissing Kind = "ssh-missing"
+
+	// KindSSHConfig denotes SSH client configuration files, including config.d drop-ins.
+	KindSSHConfig Kind = "ssh-config"
+
+	// KindSSHKey denotes SSH private and public keys.
+	KindSSHKey Kind = "ssh-key"
+
+	// KindSSHEnvironment denotes .ssh/environment files.
+	KindSSHEnvironment Kind = "ssh-environment"
+
+	// KindSSHKeyPassphrase denotes SSH private keys stored without a passphrase.
+	KindSSHKeyPassphrase Kind = "ssh-key-passphrase"
+
+	// KindSSHKeyPair denotes private keys lacking public keys, or vice versa.
+	KindSSHKeyPair Kind = "ssh-key-pair"
+
+	// KindSSHAuthorizedKeys denotes authorized_keys files.
+	KindSSHAuthorizedKeys Kind = "ssh-authorized-keys"
+
+	// KindSSHKnownHosts denotes known_hosts files.
+	KindSSHKnownHosts Kind = "ssh-known-hosts"
+
+	// KindSpecialBits denotes setuid, setgid, or sticky bits on SSH material or dotfiles.
+	KindSpecialBits Kind = "special-bits"
+
+	// KindSymlink denotes symlinked SSH material, or symlinks escaping the scan root.
+	KindSymlink Kind = "symlink"
+
+	// KindACL denotes paths whose access control lists grant access to other users or groups.
+	KindACL Kind = "acl"
+
+	// KindSecurityDescriptor denotes Windows .ssh directories and files owned or accessible beyond the user, SYSTEM, and Administrators.
+	KindSecurityDescriptor Kind = "security-descriptor"
+
+	// KindOwnership denotes files owned by another user.
+	KindOwnership Kind = "ownership"
+
+	// KindGnuPG denotes GnuPG home directories, key material, and configuration files.
+	KindGnuPG Kind = "gnupg"
+
+	// KindAWS denotes AWS CLI/SDK configuration directories and credentials.
+	KindAWS Kind = "aws"
+
+	// KindAzure denotes Azure CLI configuration directories and token caches.
+	KindAzure Kind = "azure"
+
+	// KindGCloud denotes Google Cloud CLI credential databases and application default credentials.
+	KindGCloud Kind = "gcloud"
+
+	// KindDocker denotes Docker client configuration directories and files,
+	// and the registry credentials of Podman, Buildah, and Skopeo.
+	KindDocker Kind = "docker"
+
+	// KindKube denotes Kubernetes client configuration directories and files.
+	KindKube Kind = "kube"
+
+	// KindGitCredentials denotes git credential store files.
+	KindGitCredentials Kind = "git-credentials"
+
+	// KindNetrc denotes .netrc, _netrc, and .authinfo files.
+	KindNetrc Kind = "netrc"
+
+	// KindPgpass denotes PostgreSQL .pgpass and .pg_service.conf files.
+	KindPgpass Kind = "pgpass"
+
+	// KindMySQL denotes MySQL .my.cnf and .mylogin.cnf files.
+	KindMySQL Kind = "mysql"
+
+	// KindPublishToken denotes package registry credentials, such as .npmrc, .pypirc, and the .cargo and .gem credentials files.
+	KindPublishToken Kind = "publish-token"
+
+	// KindCustom denotes paths matching user-defined rules.
+	KindCustom Kind = "custom"
+)
+
+// kindDescriptions summarizes the built-in warning kinds.
+var kindDescriptions = map[Kind]string{
+	KindPlatform:           "File systems lacking UNIX permissions",
+	KindInvisible:          "Paths missing owner read or traversal bits",
+	KindWorldWritable:      "Group-writable or world-writable paths",
+	KindHome:               "Home directories",
+	KindEtcSSH:             "/etc, /etc/ssh, and the sshd host keys, configuration, and moduli within",
+	KindSSHDir:             ".ssh directories, and directories nested directly within them",
+	KindHomeAncestor:       "Home directories and their parent directories, up to the root",
+	KindSSHAncestor:        "Parent directories of .ssh directories",
+	KindSSHMissing:         "Expected .ssh files found absent",
+	KindSSHConfig:          "SSH client configuration files, including config.d drop-ins",
+	KindSSHKey:             "SSH private and public keys",
+	KindSSHEnvironment:     ".ssh/environment files",
+	KindSSHKeyPassphrase:   "SSH private keys stored without a passphrase",
+	KindSSHKeyPair:         "Private keys lacking public keys, or vice versa",
+	KindSSHAuthorizedKeys:  "authorized_keys files",
+	KindSSHKnownHosts:      "known_hosts files",
+	KindSpecialBits:        "Setuid, setgid, or sticky bits on SSH material or dotfiles",
+	KindSymlink:            "Symlinked SSH material, or symlinks escaping the scan root",
+	KindACL:                "Paths whose access control lists grant access to other users or groups",
+	KindSecurityDescriptor: "Windows .ssh directories and files owned or accessible beyond the user, SYSTEM, and Administrators",
+	KindOwnership:          "Files owned by another user",
+	KindGnuPG:              "GnuPG home directories, key material, and configuration files",
+	KindAWS:                "AWS CLI/SDK configuration directories and credentials",
+	KindAzure:              "Azure CLI configuration directories and token caches",
+	KindGCloud:             "Google Cloud CLI credential databases and application default credentials",
+	KindDocker:             "Docker client configuration directories and files, and containers/auth.json registry credentials",
+	KindKube:               "Kubernetes client configuration directories and files",
+	KindGitCredentials:     "Git credential store files",
+	KindNetrc:              ".netrc, _netrc, and .authinfo files",
+	KindPgpass:             "PostgreSQL .pgpass and .pg_service.conf files",
+	KindMySQL:              "MySQL .my.cnf and .mylogin.cnf files",
+	KindPublishToken:       "Package registry credentials, such as .npmrc, .pypirc, and the .cargo and .gem credentials files",
+	KindCustom:             "Paths matching user-defined rules",
+}
+
+// Description summarizes the paths concerned by warnings of this kind,
+// or the empty string for kinds of custom checks.
+func (o Kind) Description() string {
+	return kindDescriptions[o]
+}
+
+// Warning describes a permission discrepancy,
+// structured for filtering by kind, severity, path, or mode,
+// without parsing messages.
+type Warning struct {
+	// Kind classifies the warning,
+	// serving as its rule identifier, as in SARIF results.
+	Kind Kind
+
+	// Severity ranks the warning.
+	Severity Severity
+
+	// Path denotes the offending file path.
+	Path string
+
+	// Message describes the discrepancy.
+	Message string
+
+	// Observed denotes warnings concerning a particular file, whose chmod bits were observed,
+	// distinguishing chmod 0000 from warnings concerning no particular file, as for missing files.
+	Observed bool
+
+	// Actual denotes the observed chmod bits, including any setuid, setgid, or sticky bits,
+	// or zero where the warning concerns no particular file.
+	Actual os.FileMode
+
+	// Expected denotes the chmod bits remediating the discrepancy,
+	// equal to Actual where chmod alone cannot remediate it.
+	Expected os.FileMode
+}
+
+// String renders a warning.
+func (o Warning) String() string {
+	return fmt.Sprintf("%s: %s", o.Path, o.Message)
+}
+
+// SortWarnings orders warnings by path, then kind, then message.
+func SortWarnings(warnings []Warning) {
+	sort.SliceStable(warnings, func(i, j int) bool {
+		a, b := warnings[i], warnings[j]
+
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		return a.Message < b.Message
+	})
+}
+
+// DedupeWarnings retains only the most severe warning per path,
+// given warnings sorted by SortWarnings.
+//
+// Ties in severity resolve to the earliest warning in sorted order,
+// that is, by kind, then by message.
+func DedupeWarnings(warnings []Warning) []Warning {
+	var deduped []Warning
+
+	for _, warning := range warnings {
+		if n := len(deduped); n != 0 && deduped[n-1].Path == warning.Path {
+			if warning.Severity > deduped[n-1].Severity {
+				deduped[n-1] = warning
+			}
+
+			continue
+		}
+
+		deduped = append(deduped, warning)
+	}
+
+	return deduped
+}
+
+// Pass describes a sensitive path found to satisfy its permission policy.
+type Pass struct {
+	// Kind classifies the inspected path.
+	Kind Kind
+
+	// Path denotes the inspected file path.
+	Path string
+
+	// Mode denotes the observed permission bits.
+	Mode os.FileMode
+}
+
+// String renders a pass.
+func (o Pass) String() string {
+	return fmt.Sprintf("%s: chmod %04o", o.Path, o.Mode)
+}
+
+// SortPasses orders passes by path, then kind.
+func SortPasses(passes []Pass) {
+	sort.SliceStable(passes, func(i, j int) bool {
+		a, b := passes[i], passes[j]
+
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		return a.Kind < b.Kind
+	})
+}